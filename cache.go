@@ -0,0 +1,200 @@
+package gofetch
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WithCache 将 Data、Pages 等获取的页面保存在 dir 目录中，每个站点一个子目录
+//
+// 缓存时间在规则的 cacheTTL 内直接使用缓存，超过后使用 ETag 和 Last-Modified 发送条件请求，
+// 响应 304 时继续使用缓存；只缓存状态码为 200 的响应
+func WithCache(dir string) Option {
+	return func(f *Fetch) error {
+		if dir == "" {
+			return errors.New("cache dir is empty")
+		}
+		f.cache = &responseCache{dir: dir, now: time.Now}
+		return nil
+	}
+}
+
+// WithOffline 只从缓存中读取页面而不发送任何请求（包括登录），没有缓存的页面返回 ErrNotCached，
+// 需要同时使用 WithCache
+func WithOffline() Option {
+	return func(f *Fetch) error {
+		f.offline = true
+		return nil
+	}
+}
+
+// cacheEntry 缓存的响应
+type cacheEntry struct {
+	URL        string      `json:"url"` // 重定向后的最终地址
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header"`
+	Body       []byte      `json:"body"`
+	Time       time.Time   `json:"time"` // 获取或最后一次验证的时间
+}
+
+// response 将缓存转换为 req 的响应
+func (e *cacheEntry) response(req *http.Request) *http.Response {
+	r := req
+	if u, err := url.Parse(e.URL); err == nil && e.URL != req.URL.String() {
+		r = req.Clone(req.Context())
+		r.URL = u
+	}
+	return &http.Response{
+		Status:        http.StatusText(e.StatusCode),
+		StatusCode:    e.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        e.Header,
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       r,
+	}
+}
+
+// responseCache 按站点和地址保存响应的磁盘缓存
+type responseCache struct {
+	dir string
+	mu  sync.Mutex
+	now func() time.Time
+}
+
+// path 返回缓存文件的路径，站点目录和文件名都使用哈希，避免 key 为 .. 等时写到 dir 之外
+func (c *responseCache) path(key, ref string) string {
+	return filepath.Join(c.dir, hashHex(key), hashHex(ref)+".json")
+}
+
+func hashHex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// load 读取缓存，没有缓存或缓存无法解析时返回 nil
+func (c *responseCache) load(key, ref string) *cacheEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	content, err := os.ReadFile(c.path(key, ref))
+	if err != nil {
+		return nil
+	}
+	e := &cacheEntry{}
+	if err := json.Unmarshal(content, e); err != nil {
+		return nil
+	}
+	return e
+}
+
+// save 保存缓存
+func (c *responseCache) save(key, ref string, e *cacheEntry) error {
+	content, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	p := c.path(key, ref)
+	dir := filepath.Dir(p)
+	err = os.MkdirAll(dir, 0700)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, ".cache-*")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(content)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), p)
+}
+
+// doCache 通过缓存获取规则匹配的页面，没有启用缓存时直接发送请求
+func (f *Fetch) doCache(cr *ConfigRule, req *http.Request) (*http.Response, error) {
+	c := f.cache
+	if c == nil {
+		return f.do(cr.Config, cr.Rule, req)
+	}
+	key, ref := cr.Config.Key, req.URL.String()
+	e := c.load(key, ref)
+	if e != nil && (f.offline || c.now().Sub(e.Time) < cr.Rule.CacheTTL) {
+		return e.response(req), nil
+	}
+	if f.offline {
+		return nil, ErrNotCached
+	}
+
+	if e != nil {
+		if etag := e.Header.Get("ETag"); etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		if lm := e.Header.Get("Last-Modified"); lm != "" {
+			req.Header.Set("If-Modified-Since", lm)
+		}
+	}
+	resp, err := f.do(cr.Config, cr.Rule, req)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && e != nil:
+		resp.Body.Close()
+		for _, k := range revalidateHeaders {
+			if vs, ok := resp.Header[k]; ok {
+				e.Header[k] = vs
+			}
+		}
+		e.Time = c.now()
+	case resp.StatusCode == http.StatusOK && !noStore(resp.Header):
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		e = &cacheEntry{resp.Request.URL.String(), resp.StatusCode, resp.Header, body, c.now()}
+	default:
+		return resp, nil
+	}
+	if err := c.save(key, ref, e); err != nil {
+		log.Println(key, err)
+	}
+	return e.response(req), nil
+}
+
+// revalidateHeaders 响应 304 时更新到缓存中的响应头，其他响应头保持不变
+var revalidateHeaders = []string{"Etag", "Last-Modified", "Date", "Expires", "Cache-Control"}
+
+// noStore 响应是否不允许缓存
+func noStore(h http.Header) bool {
+	for _, v := range h.Values("Cache-Control") {
+		for _, d := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(d), "no-store") {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,129 @@
+package gofetch
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCache(t *testing.T) {
+	var requests, notModified int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			atomic.AddInt32(&notModified, 1)
+			w.Header().Set("Etag", `"v1"`)
+			w.Header().Set("X-Revalidated", "1")
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		http.ServeFile(w, r, "./testdata/v2ex/tech.html")
+	}))
+	defer ts.Close()
+
+	dir := t.TempDir()
+	now := time.Date(2018, 3, 31, 10, 2, 0, 0, time.UTC)
+	rules := `
+rules:
+  - type: list
+    match: /.*
+    cacheTTL: 1m
+    items: div#Main > div:nth-child(2) > .item
+    itemTitle: .item_title > a
+`
+	yaml := "key: cache\nbase: " + ts.URL + "\nlogin:\n  url: /login" + rules
+	f := newTestFetch(t, yaml, WithCache(dir))
+	f.cache.now = func() time.Time { return now }
+
+	first, err := f.Data(ts.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// TTL 内直接使用缓存
+	now = now.Add(30 * time.Second)
+	second, err := f.Data(ts.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if requests != 1 || len(second.Items) != len(first.Items) || second.Meta.Header.Get("ETag") != `"v1"` {
+		t.Error("cache not used:", requests, len(second.Items))
+	}
+	// 超过 TTL 后发送条件请求
+	now = now.Add(time.Minute)
+	third, err := f.Data(ts.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if requests != 2 || notModified != 1 || len(third.Items) != len(first.Items) || third.Meta.StatusCode != http.StatusOK {
+		t.Error("revalidate not equals:", requests, notModified, third.Meta.StatusCode)
+	}
+	// 304 只更新验证和过期相关的响应头
+	if third.Meta.Header.Get("X-Revalidated") != "" || third.Meta.Header.Get("Content-Type") != first.Meta.Header.Get("Content-Type") {
+		t.Error("header not equals:", third.Meta.Header)
+	}
+
+	// 离线模式只使用缓存
+	offline := newTestFetch(t, yaml, WithCache(dir), WithOffline())
+	res, err := offline.Data(ts.URL + "/")
+	if err != nil || len(res.Items) != len(first.Items) {
+		t.Error("offline not equals:", err)
+	}
+	if _, err := offline.Data(ts.URL + "/recent"); !errors.Is(err, ErrNotCached) {
+		t.Error("error not equals:", err)
+	}
+	if _, err := offline.CreateLoginInfo("cache"); !errors.Is(err, ErrNotCached) {
+		t.Error("error not equals:", err)
+	}
+	if _, err := offline.Login("cache", &LoginInfo{Username: "a", Password: "b"}); !errors.Is(err, ErrNotCached) {
+		t.Error("error not equals:", err)
+	}
+	if requests != 2 {
+		t.Error("offline sent requests:", requests)
+	}
+}
+
+func TestCacheOptions(t *testing.T) {
+	if _, err := NewWithOptions(nil, WithOffline()); err == nil {
+		t.Error("offline without cache should fail")
+	}
+	dir := t.TempDir()
+	c := &responseCache{dir: dir, now: time.Now}
+	for _, key := range []string{"..", ".", "a/../.."} {
+		if p := c.path(key, "https://a.com/"); !strings.HasPrefix(p, dir+string(filepath.Separator)) || filepath.Dir(filepath.Dir(p)) != dir {
+			t.Error("path outside dir:", key, p)
+		}
+	}
+}
+
+func TestCacheNoStore(t *testing.T) {
+	var requests int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Header().Set("Cache-Control", "private, no-store")
+		http.ServeFile(w, r, "./testdata/v2ex/tech.html")
+	}))
+	defer ts.Close()
+
+	f := newTestFetch(t, `key: cache
+base: `+ts.URL+`
+rules:
+  - type: list
+    match: /.*
+    cacheTTL: 1h
+    items: div#Main > div:nth-child(2) > .item
+`, WithCache(t.TempDir()))
+	for i := 0; i < 2; i++ {
+		if _, err := f.Data(ts.URL + "/"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if requests != 2 {
+		t.Error("no-store response cached:", requests)
+	}
+}
@@ -0,0 +1,416 @@
+package gofetch
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andybalholm/cascadia"
+	yaml "gopkg.in/yaml.v3"
+)
+
+// ruleTypes 支持的规则类型
+var ruleTypes = map[string]bool{
+	"form":   true,
+	"index":  true,
+	"list":   true,
+	"thread": true,
+	"json":   true,
+	"feed":   true,
+}
+
+var yamlLineRe = regexp.MustCompile(`line (\d+): `)
+
+//go:embed rule/*.yaml
+var defaultRules embed.FS
+
+// configSource 通过 fs.FS 加载的规则
+type configSource struct {
+	fsys     fs.FS
+	patterns []string
+	bundled  bool
+}
+
+// WithFS 从 fsys 加载规则，patterns 可以是文件、目录或 glob 模式，目录会加载其中全部的 yaml 文件
+func WithFS(fsys fs.FS, patterns ...string) Option {
+	return func(f *Fetch) error {
+		if fsys == nil {
+			return errors.New("fs is nil")
+		}
+		f.sources = append(f.sources, configSource{fsys, patterns, false})
+		return nil
+	}
+}
+
+// WithDefaultRules 加载内置的 v2ex、hi-pda 等默认规则，同 key 的规则会覆盖默认规则
+func WithDefaultRules() Option {
+	return func(f *Fetch) error {
+		f.sources = append(f.sources, configSource{defaultRules, []string{"rule"}, true})
+		return nil
+	}
+}
+
+// NewDefault 使用内置的默认规则创建数据获取实例
+func NewDefault(opts ...Option) (*Fetch, error) {
+	return NewWithOptions(nil, append([]Option{WithDefaultRules()}, opts...)...)
+}
+
+// loadConfigs 加载 patterns 对应的全部规则文件，fsys 为 nil 时从本地文件加载
+func (f *Fetch) loadConfigs(fsys fs.FS, patterns []string, bundled bool) []*ConfigError {
+	files, errs := expandConfigPaths(fsys, patterns)
+	for _, file := range files {
+		if fsys != nil {
+			_, ces := f.loadConfig(fsys, file, bundled)
+			errs = append(errs, ces...)
+			continue
+		}
+		info, _ := os.Stat(file)
+		config, ces := f.loadConfig(nil, file, bundled)
+		errs = append(errs, ces...)
+		key := ""
+		if config != nil {
+			key = config.Key
+		}
+		f.watchFile(file, info, key)
+	}
+	return errs
+}
+
+// loadConfig 读取、解析并保存单个规则文件
+func (f *Fetch) loadConfig(fsys fs.FS, file string, bundled bool) (*Config, []*ConfigError) {
+	var content []byte
+	var err error
+	if fsys == nil {
+		content, err = os.ReadFile(file)
+	} else {
+		content, err = fs.ReadFile(fsys, file)
+	}
+	if err != nil {
+		log.Println(err)
+		return nil, []*ConfigError{{file, 0, err.Error()}}
+	}
+
+	config, errs := parseConfig(file, content)
+	for _, ce := range errs {
+		log.Println(ce)
+	}
+	if config == nil {
+		return nil, errs
+	}
+	config.fsys = fsys
+	config.bundled = bundled
+
+	if config.Key != "" {
+		old, ok := f.Config[config.Key]
+		if ok && !old.bundled && bundled {
+			// 默认规则在用户规则之后加载时不覆盖用户规则
+			return config, errs
+		}
+		if ok && !old.bundled {
+			ce := &ConfigError{file, config.keyLine, fmt.Sprintf("duplicate key %q, first defined in %s:%d", config.Key, old.source, old.keyLine)}
+			log.Println(ce)
+			errs = append(errs, ce)
+		}
+		f.setConfig(config)
+	}
+	return config, errs
+}
+
+// expandConfigPaths 展开目录和 glob 模式，fsys 为 nil 时使用本地文件
+func expandConfigPaths(fsys fs.FS, patterns []string) ([]string, []*ConfigError) {
+	glob := filepath.Glob
+	stat := os.Stat
+	readDir := os.ReadDir
+	join := filepath.Join
+	if fsys != nil {
+		glob = func(pattern string) ([]string, error) { return fs.Glob(fsys, pattern) }
+		stat = func(name string) (fs.FileInfo, error) { return fs.Stat(fsys, name) }
+		readDir = func(name string) ([]fs.DirEntry, error) { return fs.ReadDir(fsys, name) }
+		join = path.Join
+	}
+
+	var files []string
+	var errs []*ConfigError
+	for _, pattern := range patterns {
+		if strings.ContainsAny(pattern, "*?[") {
+			matches, err := glob(pattern)
+			if err != nil {
+				errs = append(errs, &ConfigError{pattern, 0, err.Error()})
+				continue
+			}
+			if len(matches) == 0 {
+				log.Println(pattern, "no files match")
+				errs = append(errs, &ConfigError{pattern, 0, "no files match"})
+			}
+			files = append(files, matches...)
+			continue
+		}
+		info, err := stat(pattern)
+		if err != nil || !info.IsDir() {
+			files = append(files, pattern)
+			continue
+		}
+		entries, err := readDir(pattern)
+		if err != nil {
+			errs = append(errs, &ConfigError{pattern, 0, err.Error()})
+			continue
+		}
+		for _, entry := range entries {
+			ext := path.Ext(entry.Name())
+			if !entry.IsDir() && (ext == ".yaml" || ext == ".yml") {
+				files = append(files, join(pattern, entry.Name()))
+			}
+		}
+	}
+	return files, errs
+}
+
+// parseConfig 解析规则文件，返回规则和文件中的全部错误
+func parseConfig(file string, content []byte) (*Config, []*ConfigError) {
+	root := &yaml.Node{}
+	err := yaml.Unmarshal(content, root)
+	if err != nil {
+		return nil, yamlErrors(file, err)
+	}
+	config := &Config{source: file}
+	err = root.Decode(config)
+	if err != nil {
+		return nil, yamlErrors(file, err)
+	}
+	errs := validateConfig(file, root, config)
+	if len(root.Content) > 0 {
+		config.keyLine = yamlAt(root.Content[0], "key").Line
+	}
+	return config, errs
+}
+
+// yamlErrors 将 yaml 的错误转换为带行号的错误
+func yamlErrors(file string, err error) []*ConfigError {
+	msgs := []string{err.Error()}
+	if te, ok := err.(*yaml.TypeError); ok {
+		msgs = te.Errors
+	}
+	var errs []*ConfigError
+	for _, msg := range msgs {
+		msg = strings.TrimPrefix(msg, "yaml: ")
+		line := 0
+		if m := yamlLineRe.FindStringSubmatchIndex(msg); m != nil {
+			line, _ = strconv.Atoi(msg[m[2]:m[3]])
+			msg = msg[:m[0]] + msg[m[1]:]
+		}
+		errs = append(errs, &ConfigError{file, line, msg})
+	}
+	return errs
+}
+
+// validateConfig 检查规则的语义，root 用于定位错误所在的行
+func validateConfig(file string, root *yaml.Node, config *Config) []*ConfigError {
+	var errs []*ConfigError
+	addError := func(node *yaml.Node, format string, args ...interface{}) {
+		line := 0
+		if node != nil {
+			line = node.Line
+		}
+		errs = append(errs, &ConfigError{file, line, fmt.Sprintf(format, args...)})
+	}
+
+	doc := root
+	if doc.Kind == yaml.DocumentNode && len(doc.Content) > 0 {
+		doc = doc.Content[0]
+	}
+	if config.Key == "" {
+		addError(doc, "missing key")
+	}
+	base, err := url.Parse(config.Base)
+	if err != nil || !base.IsAbs() || base.Host == "" {
+		addError(yamlAt(doc, "base"), "base %q is not an absolute URL", config.Base)
+	}
+	if config.RateLimit != nil {
+		if err := config.RateLimit.validate(); err != nil {
+			addError(yamlAt(doc, "rateLimit"), "rateLimit: %v", err)
+		}
+	}
+	if err := validateHeaders(config.Headers); err != nil {
+		addError(yamlAt(doc, "headers"), "headers: %v", err)
+	}
+	if err := validateHeaders(config.Login.Headers); err != nil {
+		addError(yamlAt(yamlValue(doc, "login"), "headers"), "login.headers: %v", err)
+	}
+	if config.Client != nil && config.Client.Proxy != "" {
+		if _, err := parseProxy(config.Client.Proxy); err != nil {
+			addError(yamlAt(yamlValue(doc, "client"), "proxy"), "client.proxy: %v", err)
+		}
+	}
+	if config.Retry != nil {
+		if err := config.Retry.validate(); err != nil {
+			addError(yamlAt(doc, "retry"), "retry: %v", err)
+		}
+	}
+	if _, err := time.LoadLocation(config.TimeZone); err != nil {
+		addError(yamlAt(doc, "timezone"), "timezone %q: %v", config.TimeZone, err)
+	}
+	if config.Index.Category != nil {
+		node := yamlAt(yamlValue(yamlValue(doc, "index"), "category"), "items")
+		validateSelector(config.Index.Category.Items, func(err error) {
+			addError(node, "index.category.items: %v", err)
+		})
+	}
+	replaceNode := yamlValue(yamlValue(doc, "login"), "replace")
+	for k, r := range config.Login.Replace {
+		if len(r) != 2 {
+			addError(yamlAt(replaceNode, k), "login.replace.%s: expected regexp and replacement", k)
+			continue
+		}
+		if _, err := regexp.Compile(r[0]); err != nil {
+			addError(yamlAt(replaceNode, k), "login.replace.%s: %v", k, err)
+		}
+	}
+
+	convertNode := yamlValue(yamlValue(doc, "login"), "convert")
+	for k, cts := range config.Login.Convert {
+		if err := validateConvert(cts); err != nil {
+			addError(yamlAt(convertNode, k), "login.convert.%s: %v", k, err)
+		}
+	}
+
+	rulesNode := yamlValue(doc, "rules")
+	for i, rule := range config.Rules {
+		var node *yaml.Node
+		if rulesNode != nil && i < len(rulesNode.Content) {
+			node = rulesNode.Content[i]
+		}
+		if rule == nil {
+			addError(node, "rules[%d]: empty rule", i)
+			continue
+		}
+		if rule.Type == "" || !ruleTypes[rule.Type] && len(rule.Fields) == 0 && len(rule.Content) == 0 {
+			addError(yamlAt(node, "type"), "rules[%d]: unknown rule type %q", i, rule.Type)
+		}
+		format := rule.format()
+		if format != "html" && format != "json" {
+			addError(yamlAt(node, "format"), "rules[%d]: unknown format %q", i, rule.Format)
+		} else if format == "json" && (rule.Type == "form" || rule.Type == "feed") {
+			addError(yamlAt(node, "format"), "rules[%d]: %s rules do not support json", i, rule.Type)
+		}
+		if rule.Type == "feed" && (len(rule.Fields) > 0 || len(rule.Content) > 0) {
+			addError(node, "rules[%d]: feed rules do not support fields", i)
+		}
+		if rule.Match == "" {
+			addError(node, "rules[%d]: missing match", i)
+		} else if _, err := regexp.Compile("^" + rule.Match + "$"); err != nil {
+			addError(yamlAt(node, "match"), "rules[%d].match: %v", i, err)
+		}
+		for k, v := range rule.Selectors {
+			if format == "json" {
+				continue
+			}
+			validateSelector(v, func(err error) {
+				addError(yamlAt(node, k), "rules[%d].%s: %v", i, k, err)
+			})
+		}
+		if p := rule.Pagination; p != nil {
+			pageNode := yamlValue(node, "pagination")
+			if err := p.validate(); err != nil {
+				addError(yamlAt(node, "pagination"), "rules[%d].pagination: %v", i, err)
+			}
+			if p.Next != "" && format == "html" {
+				validateSelector(p.Next, func(err error) {
+					addError(yamlAt(pageNode, "next"), "rules[%d].pagination.next: %v", i, err)
+				})
+			}
+		}
+		if rule.CacheTTL < 0 {
+			addError(yamlAt(node, "cacheTTL"), "rules[%d].cacheTTL: %v is negative", i, rule.CacheTTL)
+		}
+		if err := validateHeaders(rule.Headers); err != nil {
+			addError(yamlAt(node, "headers"), "rules[%d].headers: %v", i, err)
+		}
+		for k, ft := range rule.Types {
+			if ft == nil {
+				continue
+			}
+			if err := ft.validate(); err != nil {
+				addError(yamlAt(yamlValue(node, "types"), k), "rules[%d].types.%s: %v", i, k, err)
+			}
+		}
+		for k, cts := range rule.Convert {
+			if err := validateConvert(cts); err != nil {
+				addError(yamlAt(yamlValue(node, "convert"), k), "rules[%d].convert.%s: %v", i, k, err)
+			}
+		}
+		for _, name := range []string{"fields", "content"} {
+			fields := rule.Fields
+			if name == "content" {
+				fields = rule.Content
+			}
+			fieldsNode := yamlValue(node, name)
+			for j, field := range fields {
+				var fieldNode *yaml.Node
+				if fieldsNode != nil && j < len(fieldsNode.Content) {
+					fieldNode = fieldsNode.Content[j]
+				}
+				if field == nil {
+					addError(fieldNode, "rules[%d].%s[%d]: empty field", i, name, j)
+					continue
+				}
+				if err := field.validate(format); err != nil {
+					addError(fieldNode, "rules[%d].%s[%d]: %v", i, name, j, err)
+				}
+				if field.Selector != "" && format == "html" {
+					validateSelector(field.Selector, func(err error) {
+						addError(yamlAt(fieldNode, "selector"), "rules[%d].%s[%d].selector: %v", i, name, j, err)
+					})
+				}
+			}
+		}
+	}
+	sort.SliceStable(errs, func(i, j int) bool {
+		return errs[i].Line < errs[j].Line
+	})
+	return errs
+}
+
+// validateSelector 检查 CSS 选择器或以 xpath: 开头的 XPath 表达式能否被解析
+func validateSelector(sel string, fn func(error)) {
+	if expr, ok := strings.CutPrefix(sel, xpathPrefix); ok {
+		if _, err := compileXPath(expr); err != nil {
+			fn(err)
+		}
+		return
+	}
+	if _, err := cascadia.ParseGroup(sel); err != nil {
+		fn(err)
+	}
+}
+
+// yamlValue 获取 mapping 节点中 key 对应的值节点，不存在时返回 nil
+func yamlValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// yamlAt 获取 key 对应的值节点，不存在时返回 node 本身，用于定位错误所在的行
+func yamlAt(node *yaml.Node, key string) *yaml.Node {
+	if v := yamlValue(node, key); v != nil {
+		return v
+	}
+	return node
+}
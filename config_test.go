@@ -0,0 +1,129 @@
+package gofetch
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestNewStrict(t *testing.T) {
+	f, err := NewWithOptions([]string{"./rule/v2ex.yaml", "./rule/hipda.yaml"}, WithStrict())
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(f.Config) != 2 {
+		t.Error("config len not equals 2:", len(f.Config))
+	}
+}
+
+func TestNewStrictErrors(t *testing.T) {
+	_, err := NewWithOptions([]string{
+		"./testdata/invalid/hipda.yaml",
+		"./rule/hipda.yaml",
+		"./testdata/error.yaml",
+		"./rule/xxxx.yaml",
+	}, WithStrict())
+	var errs ConfigErrors
+	if !errors.As(err, &errs) {
+		t.Error("error not equals ConfigErrors:", err)
+		return
+	}
+	prefixes := []string{
+		"./testdata/invalid/hipda.yaml:2: base \"/forum\" is not an absolute URL",
+		"./testdata/invalid/hipda.yaml:6: index.category.items: ",
+		"./testdata/invalid/hipda.yaml:11: login.replace.captchaImgUrl: error parsing regexp: ",
+		"./testdata/invalid/hipda.yaml:15: rules[0]: unknown rule type \"lst\"",
+		"./testdata/invalid/hipda.yaml:20: rules[1]: missing match",
+		"./testdata/invalid/hipda.yaml:22: rules[1].itemContent: ",
+		"./testdata/invalid/hipda.yaml:25: rules[2].match: error parsing regexp: ",
+		"./testdata/invalid/hipda.yaml:26: timezone \"Mars/Olympus\": ",
+		"./rule/hipda.yaml:1: duplicate key \"hipda\", first defined in ./testdata/invalid/hipda.yaml:1",
+		"./testdata/error.yaml: mapping values are not allowed in this context",
+		"./rule/xxxx.yaml: open ./rule/xxxx.yaml: ",
+	}
+	if len(errs) != len(prefixes) {
+		t.Error("errors len not equals:", len(errs), err)
+		return
+	}
+	for i, prefix := range prefixes {
+		if !strings.HasPrefix(errs[i].Error(), prefix) {
+			t.Error("error not equals:", errs[i], "!=", prefix)
+		}
+	}
+
+	var ce *ConfigError
+	if !errors.As(err, &ce) || ce.Line != 2 {
+		t.Error("error not equals ConfigError:", ce)
+	}
+}
+
+func TestNewDirAndGlob(t *testing.T) {
+	for _, path := range []string{"./rule", "./rule/*.yaml"} {
+		f, err := NewWithOptions([]string{path}, WithStrict())
+		if err != nil {
+			t.Error(path, err)
+			continue
+		}
+		if f.Config["v2ex"] == nil || f.Config["hipda"] == nil {
+			t.Error(path, "config not found")
+		}
+	}
+
+	_, err := NewWithOptions([]string{"./rule/*.yml"}, WithStrict())
+	if err == nil {
+		t.Error("glob without match must fail")
+	}
+}
+
+func TestWithFS(t *testing.T) {
+	content, _ := os.ReadFile("./rule/v2ex.yaml")
+	fsys := fstest.MapFS{
+		"sites/v2ex.yaml": {Data: content},
+		"sites/README.md": {Data: []byte("# rules")},
+	}
+	f, err := NewWithOptions(nil, WithFS(fsys, "sites"), WithStrict())
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(f.Config) != 1 || f.Config["v2ex"] == nil {
+		t.Error("config not equals:", f.Config)
+	}
+}
+
+func TestNewDefault(t *testing.T) {
+	f, err := NewDefault(WithStrict())
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if f.Config["v2ex"] == nil || f.Config["hipda"] == nil {
+		t.Error("default config not found")
+		return
+	}
+
+	f, err = NewWithOptions([]string{"./testdata/override/v2ex.yaml"}, WithDefaultRules(), WithStrict())
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if f.Config["v2ex"].Index.URL != "/?tab=hot" {
+		t.Error("default config not overridden:", f.Config["v2ex"].Index.URL)
+	}
+
+	// WithDefaultRules 在 WithFS 之后时同样不覆盖用户规则
+	f, err = NewWithOptions(nil, WithFS(os.DirFS("testdata/override"), "v2ex.yaml"), WithDefaultRules(), WithStrict())
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if c := f.Config["v2ex"]; c.bundled || c.source != "v2ex.yaml" || c.Index.URL != "/?tab=hot" {
+		t.Error("default config overrides user config:", c.source, c.Index.URL)
+	}
+	if f.Config["hipda"] == nil || !f.Config["hipda"].bundled {
+		t.Error("default config not loaded")
+	}
+}
@@ -0,0 +1,220 @@
+package gofetch
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// converter 处理函数，每个转换规则为 [名称, 参数...]，处理的值可以被 split 拆分为多个
+type converter struct {
+	min, max int // 参数个数
+	fn       func(vs []string, args []string) []string
+}
+
+var converters = map[string]*converter{
+	"hex": each(0, 0, func(s string, _ []string) string {
+		return hex.EncodeToString([]byte(s))
+	}),
+	"md5": each(0, 0, func(s string, _ []string) string {
+		hash := md5.Sum([]byte(s))
+		return hex.EncodeToString(hash[:])
+	}),
+	"trim": each(0, 1, func(s string, args []string) string {
+		if len(args) == 0 {
+			return strings.TrimSpace(s)
+		}
+		return strings.Trim(s, args[0])
+	}),
+	"trimPrefix": each(1, 1, func(s string, args []string) string {
+		return strings.TrimPrefix(s, args[0])
+	}),
+	"trimSuffix": each(1, 1, func(s string, args []string) string {
+		return strings.TrimSuffix(s, args[0])
+	}),
+	"collapse": each(0, 0, func(s string, _ []string) string {
+		return strings.Join(strings.Fields(s), " ")
+	}),
+	"replace": each(2, 2, func(s string, args []string) string {
+		return compileRegexp(args[0]).ReplaceAllString(s, args[1])
+	}),
+	"capture": each(1, 2, func(s string, args []string) string {
+		re := compileRegexp(args[0])
+		m := re.FindStringSubmatch(s)
+		// 默认取第一个分组，正则没有分组时取整个匹配
+		group := min(1, re.NumSubexp())
+		if len(args) > 1 {
+			group, _ = strconv.Atoi(args[1])
+		}
+		if group < 0 || group >= len(m) {
+			return ""
+		}
+		return m[group]
+	}),
+	"lower": each(0, 0, func(s string, _ []string) string {
+		return strings.ToLower(s)
+	}),
+	"upper": each(0, 0, func(s string, _ []string) string {
+		return strings.ToUpper(s)
+	}),
+	"prepend": each(1, 1, func(s string, args []string) string {
+		return args[0] + s
+	}),
+	"append": each(1, 1, func(s string, args []string) string {
+		return s + args[0]
+	}),
+	"query": each(1, 1, func(s string, args []string) string {
+		u, err := url.Parse(s)
+		if err != nil {
+			return ""
+		}
+		return u.Query().Get(args[0])
+	}),
+	"unescape": each(0, 0, func(s string, _ []string) string {
+		return html.UnescapeString(s)
+	}),
+	"urlDecode": each(0, 0, func(s string, _ []string) string {
+		v, err := url.QueryUnescape(s)
+		if err != nil {
+			return s
+		}
+		return v
+	}),
+	"default": each(1, 1, func(s string, args []string) string {
+		if s == "" {
+			return args[0]
+		}
+		return s
+	}),
+	"split": {1, 1, func(vs []string, args []string) []string {
+		var r []string
+		for _, v := range vs {
+			r = append(r, strings.Split(v, args[0])...)
+		}
+		return r
+	}},
+	"index": {1, 1, func(vs []string, args []string) []string {
+		i, _ := strconv.Atoi(args[0])
+		if i < 0 {
+			i += len(vs)
+		}
+		if i < 0 || i >= len(vs) {
+			return []string{""}
+		}
+		return vs[i : i+1]
+	}},
+	"join": {0, 1, func(vs []string, args []string) []string {
+		sep := ""
+		if len(args) > 0 {
+			sep = args[0]
+		}
+		return []string{strings.Join(vs, sep)}
+	}},
+	"compact": {0, 0, func(vs []string, _ []string) []string {
+		var r []string
+		for _, v := range vs {
+			if strings.TrimSpace(v) != "" {
+				r = append(r, v)
+			}
+		}
+		return r
+	}},
+}
+
+// each 创建逐个处理值的转换函数
+func each(min, max int, fn func(s string, args []string) string) *converter {
+	return &converter{min, max, func(vs []string, args []string) []string {
+		r := make([]string, len(vs))
+		for i, v := range vs {
+			r[i] = fn(v, args)
+		}
+		return r
+	}}
+}
+
+var regexpCache sync.Map
+
+// compileRegexp 编译并缓存正则表达式，规则加载时已经校验过，无法编译时返回不匹配任何内容的正则
+func compileRegexp(expr string) *regexp.Regexp {
+	if re, ok := regexpCache.Load(expr); ok {
+		return re.(*regexp.Regexp)
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		re = regexp.MustCompile(`[^\s\S]`)
+	}
+	regexpCache.Store(expr, re)
+	return re
+}
+
+// convertString 按顺序执行转换规则，split 拆分后剩余的多个值使用 "," 连接
+func convertString(s string, cts [][]string) string {
+	vs := []string{s}
+	for _, ct := range cts {
+		if len(ct) < 1 {
+			continue
+		}
+		c, ok := converters[ct[0]]
+		if !ok || len(ct)-1 < c.min {
+			continue
+		}
+		vs = c.fn(vs, ct[1:])
+	}
+	return strings.Join(vs, ",")
+}
+
+// validateConvert 检查转换规则的名称、参数个数和正则表达式
+func validateConvert(cts [][]string) error {
+	for i, ct := range cts {
+		if len(ct) < 1 {
+			return fmt.Errorf("convert[%d]: empty", i)
+		}
+		c, ok := converters[ct[0]]
+		if !ok {
+			return fmt.Errorf("convert[%d]: unknown converter %q", i, ct[0])
+		}
+		n := len(ct) - 1
+		if n < c.min || n > c.max {
+			return fmt.Errorf("convert[%d]: %s expects %d to %d arguments, got %d", i, ct[0], c.min, c.max, n)
+		}
+		switch ct[0] {
+		case "replace", "capture":
+			re, err := regexp.Compile(ct[1])
+			if err != nil {
+				return fmt.Errorf("convert[%d]: %v", i, err)
+			}
+			if ct[0] == "capture" && n == 2 {
+				if group, err := strconv.Atoi(ct[2]); err == nil && group > re.NumSubexp() {
+					return fmt.Errorf("convert[%d]: group %d out of range, %q has %d groups", i, group, ct[1], re.NumSubexp())
+				}
+			}
+		case "index":
+			if _, err := strconv.Atoi(ct[1]); err != nil {
+				return fmt.Errorf("convert[%d]: index %q is not a number", i, ct[1])
+			}
+		}
+		if ct[0] == "capture" && n == 2 {
+			if group, err := strconv.Atoi(ct[2]); err != nil {
+				return fmt.Errorf("convert[%d]: group %q is not a number", i, ct[2])
+			} else if group < 0 {
+				return fmt.Errorf("convert[%d]: group %d is negative", i, group)
+			}
+		}
+	}
+	return nil
+}
+
+// convertMap 对 m 中同名的字段执行转换规则
+func convertMap(m map[string]string, convert map[string][][]string) {
+	for k, cts := range convert {
+		if v, ok := m[k]; ok {
+			m[k] = convertString(v, cts)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package gofetch
+
+import "testing"
+
+func TestConvertString(t *testing.T) {
+	cases := []struct {
+		s   string
+		cts [][]string
+		one string
+	}{
+		{"abc", [][]string{{"md5"}}, "900150983cd24fb0d6963f7d28e17f72"},
+		{"abc", [][]string{{"hex"}}, "616263"},
+		{"  a \n\t b  ", [][]string{{"collapse"}}, "a b"},
+		{"  a  ", [][]string{{"trim"}}, "a"},
+		{"[a]", [][]string{{"trim", "[]"}}, "a"},
+		{"214 回复", [][]string{{"trimSuffix", " 回复"}}, "214"},
+		{"共 214 回复", [][]string{{"capture", `(\d+)`}}, "214"},
+		{"2018-3-31", [][]string{{"capture", `(\d+)-(\d+)`, "2"}}, "3"},
+		{"a", [][]string{{"capture", "(a)", "-1"}}, ""},
+		{"abc123", [][]string{{"capture", `\d+`}}, "123"},
+		{"2018-3-31", [][]string{{"replace", `(\d+)-(\d+)-(\d+)`, "$3/$2/$1"}}, "31/3/2018"},
+		{"a,b,c", [][]string{{"split", ","}, {"index", "-1"}}, "c"},
+		{"a b  c", [][]string{{"split", " "}, {"compact"}, {"join", "|"}}, "a|b|c"},
+		{"a b", [][]string{{"split", " "}, {"upper"}}, "A,B"},
+		{"AbC", [][]string{{"lower"}}, "abc"},
+		{"/forumdisplay.php?fid=5&page=2", [][]string{{"query", "fid"}}, "5"},
+		{"&lt;b&gt;", [][]string{{"unescape"}}, "<b>"},
+		{"%CB%EF", [][]string{{"urlDecode"}, {"hex"}}, "cbef"},
+		{"", [][]string{{"default", "0"}}, "0"},
+		{"1", [][]string{{"prepend", "#"}, {"append", "楼"}}, "#1楼"},
+	}
+	for _, c := range cases {
+		if v := convertString(c.s, c.cts); v != c.one {
+			t.Error("convert not equals:", c.s, c.cts, v, "!=", c.one)
+		}
+	}
+}
+
+func TestValidateConvert(t *testing.T) {
+	for _, cts := range [][][]string{
+		{{}},
+		{{"xxx"}},
+		{{"md5", "a"}},
+		{{"replace", "("}},
+		{{"replace", "a", "b", "c"}},
+		{{"index", "a"}},
+		{{"capture", `\d`, "a"}},
+		{{"capture", "(a)", "-1"}},
+		{{"capture", `\d+`, "1"}},
+	} {
+		if validateConvert(cts) == nil {
+			t.Error("convert must be invalid:", cts)
+		}
+	}
+	if err := validateConvert([][]string{{"trim"}, {"capture", `\d+`}, {"split", ","}, {"index", "0"}}); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,272 @@
+package gofetch
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// Cookie 保存在 CookieJar 中的 cookie
+type Cookie struct {
+	Name     string    `json:"name"`
+	Value    string    `json:"value"`
+	Domain   string    `json:"domain"`
+	Path     string    `json:"path"`
+	Expires  time.Time `json:"expires"` // 零值表示会话 cookie
+	Secure   bool      `json:"secure,omitempty"`
+	HttpOnly bool      `json:"httpOnly,omitempty"`
+	HostOnly bool      `json:"hostOnly,omitempty"`
+
+	seq uint64
+}
+
+// CookieJar 站点的 cookie 存储，domain（包括公共后缀检查）、path 和过期时间的处理与 net/http/cookiejar 一致，
+// 可以安全地在多个 goroutine 中使用
+type CookieJar struct {
+	mu      sync.Mutex
+	entries map[string]*Cookie
+	seq     uint64
+	version uint64
+	now     func() time.Time
+}
+
+// NewCookieJar 创建 cookie 存储
+func NewCookieJar() *CookieJar {
+	return &CookieJar{
+		entries: make(map[string]*Cookie),
+		now:     time.Now,
+	}
+}
+
+// SetCookies 实现 http.CookieJar，保存 u 的响应中设置的 cookie
+func (j *CookieJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	host, err := canonicalHost(u.Host)
+	if err != nil {
+		return
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	now := j.now()
+	for _, c := range cookies {
+		e, remove, ok := newCookieEntry(c, host, defaultPath(u.Path), now)
+		if !ok {
+			continue
+		}
+		id := e.id()
+		j.version++
+		if remove {
+			delete(j.entries, id)
+			continue
+		}
+		if old, ok := j.entries[id]; ok {
+			e.seq = old.seq
+		} else {
+			j.seq++
+			e.seq = j.seq
+		}
+		j.entries[id] = e
+	}
+}
+
+// Cookies 实现 http.CookieJar，返回请求 u 时需要发送的 cookie
+func (j *CookieJar) Cookies(u *url.URL) []*http.Cookie {
+	host, err := canonicalHost(u.Host)
+	if err != nil {
+		return nil
+	}
+	https := u.Scheme == "https"
+	path := u.Path
+	if path == "" {
+		path = "/"
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	now := j.now()
+	var selected []*Cookie
+	for id, e := range j.entries {
+		if e.expired(now) {
+			delete(j.entries, id)
+			continue
+		}
+		if e.Secure && !https {
+			continue
+		}
+		if !e.domainMatch(host) || !pathMatch(e.Path, path) {
+			continue
+		}
+		selected = append(selected, e)
+	}
+	sort.Slice(selected, func(a, b int) bool {
+		if len(selected[a].Path) != len(selected[b].Path) {
+			return len(selected[a].Path) > len(selected[b].Path)
+		}
+		return selected[a].seq < selected[b].seq
+	})
+
+	cookies := make([]*http.Cookie, 0, len(selected))
+	for _, e := range selected {
+		cookies = append(cookies, &http.Cookie{Name: e.Name, Value: e.Value})
+	}
+	return cookies
+}
+
+// All 返回全部未过期的 cookie
+func (j *CookieJar) All() []*Cookie {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	now := j.now()
+	cookies := make([]*Cookie, 0, len(j.entries))
+	for id, e := range j.entries {
+		if e.expired(now) {
+			delete(j.entries, id)
+			continue
+		}
+		c := *e
+		cookies = append(cookies, &c)
+	}
+	sort.Slice(cookies, func(a, b int) bool {
+		return cookies[a].seq < cookies[b].seq
+	})
+	return cookies
+}
+
+// Add 直接添加 cookie，同 domain、path、name 的 cookie 会被替换
+func (j *CookieJar) Add(cookies ...*Cookie) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	now := j.now()
+	for _, c := range cookies {
+		e := *c
+		e.Domain = strings.TrimPrefix(strings.ToLower(e.Domain), ".")
+		if e.Path == "" {
+			e.Path = "/"
+		}
+		if e.Name == "" || e.Domain == "" || e.expired(now) {
+			continue
+		}
+		id := e.id()
+		j.version++
+		if old, ok := j.entries[id]; ok {
+			e.seq = old.seq
+		} else {
+			j.seq++
+			e.seq = j.seq
+		}
+		j.entries[id] = &e
+	}
+}
+
+// Clear 清除全部 cookie
+func (j *CookieJar) Clear() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.entries = make(map[string]*Cookie)
+	j.version++
+}
+
+// changes 返回 cookie 的修改次数
+func (j *CookieJar) changes() uint64 {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.version
+}
+
+func (c *Cookie) id() string {
+	return c.Domain + ";" + c.Path + ";" + c.Name
+}
+
+func (c *Cookie) expired(now time.Time) bool {
+	return !c.Expires.IsZero() && !c.Expires.After(now)
+}
+
+func (c *Cookie) domainMatch(host string) bool {
+	if c.Domain == host {
+		return true
+	}
+	return !c.HostOnly && strings.HasSuffix(host, "."+c.Domain)
+}
+
+// newCookieEntry 根据响应中的 cookie 创建存储项，remove 为 true 时表示需要删除已有的 cookie
+func newCookieEntry(c *http.Cookie, host, path string, now time.Time) (e *Cookie, remove, ok bool) {
+	e = &Cookie{
+		Name:     c.Name,
+		Value:    c.Value,
+		Path:     c.Path,
+		Secure:   c.Secure,
+		HttpOnly: c.HttpOnly,
+	}
+	if e.Path == "" || e.Path[0] != '/' {
+		e.Path = path
+	}
+
+	domain := strings.TrimPrefix(strings.ToLower(c.Domain), ".")
+	switch {
+	case domain == "" || domain == host:
+		e.Domain = host
+		e.HostOnly = domain == ""
+	case net.ParseIP(host) != nil:
+		return nil, false, false
+	case !strings.HasSuffix(host, "."+domain) || !strings.Contains(domain, "."):
+		return nil, false, false
+	case isPublicSuffix(domain):
+		// 与 net/http/cookiejar 一致，不允许为 com.cn、co.uk 等公共后缀设置 cookie
+		return nil, false, false
+	default:
+		e.Domain = domain
+	}
+
+	switch {
+	case c.MaxAge < 0:
+		return e, true, true
+	case c.MaxAge > 0:
+		e.Expires = now.Add(time.Duration(c.MaxAge) * time.Second)
+	case !c.Expires.IsZero():
+		if !c.Expires.After(now) {
+			return e, true, true
+		}
+		e.Expires = c.Expires
+	}
+	return e, false, true
+}
+
+// isPublicSuffix 判断 domain 是否为公共后缀，包括 github.io 等私有注册的后缀
+func isPublicSuffix(domain string) bool {
+	return publicsuffix.List.PublicSuffix(domain) == domain
+}
+
+func canonicalHost(host string) (string, error) {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.Trim(host, "[]")
+	if host == "" {
+		return "", errors.New("host is empty")
+	}
+	return strings.ToLower(strings.TrimSuffix(host, ".")), nil
+}
+
+func defaultPath(path string) string {
+	i := strings.LastIndex(path, "/")
+	if i <= 0 {
+		return "/"
+	}
+	return path[:i]
+}
+
+func pathMatch(cookiePath, requestPath string) bool {
+	if requestPath == cookiePath {
+		return true
+	}
+	if !strings.HasPrefix(requestPath, cookiePath) {
+		return false
+	}
+	return strings.HasSuffix(cookiePath, "/") || requestPath[len(cookiePath)] == '/'
+}
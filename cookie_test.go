@@ -0,0 +1,131 @@
+package gofetch
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+)
+
+func cookieNames(cs []*http.Cookie) string {
+	s := ""
+	for _, c := range cs {
+		s += c.Name + "=" + c.Value + ";"
+	}
+	return s
+}
+
+func TestCookieJar(t *testing.T) {
+	now := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	jar := NewCookieJar()
+	jar.now = func() time.Time { return now }
+
+	u, _ := url.Parse("https://www.hi-pda.com/forum/index.php")
+	jar.SetCookies(u, []*http.Cookie{
+		{Name: "sid", Value: "a"},
+		{Name: "auth", Value: "b", Path: "/forum/", MaxAge: 60},
+		{Name: "other", Value: "c", Path: "/other"},
+		{Name: "domain", Value: "d", Domain: ".hi-pda.com"},
+		{Name: "secure", Value: "e", Secure: true},
+		{Name: "foreign", Value: "f", Domain: "v2ex.com"},
+		{Name: "old", Value: "g", Expires: now.Add(-time.Hour)},
+	})
+
+	s := cookieNames(jar.Cookies(u))
+	if s != "auth=b;sid=a;domain=d;secure=e;" {
+		t.Error("cookies not equals:", s)
+	}
+
+	sub, _ := url.Parse("http://img.hi-pda.com/forum/a.png")
+	s = cookieNames(jar.Cookies(sub))
+	if s != "domain=d;" {
+		t.Error("sub domain cookies not equals:", s)
+	}
+
+	jar.SetCookies(u, []*http.Cookie{{Name: "sid", Value: "x", MaxAge: -1}})
+	now = now.Add(2 * time.Minute)
+	s = cookieNames(jar.Cookies(u))
+	if s != "domain=d;secure=e;" {
+		t.Error("expired cookies not removed:", s)
+	}
+	if l := len(jar.All()); l != 3 {
+		t.Error("all len not equals 3:", l)
+	}
+}
+
+func TestCookieJarPublicSuffix(t *testing.T) {
+	jar := NewCookieJar()
+	u, _ := url.Parse("https://bbs.example.com.cn/forum/index.php")
+	jar.SetCookies(u, []*http.Cookie{
+		{Name: "suffix", Value: "a", Domain: "com.cn"},
+		{Name: "tld", Value: "b", Domain: ".cn"},
+		{Name: "site", Value: "c", Domain: "example.com.cn"},
+	})
+	if s := cookieNames(jar.Cookies(u)); s != "site=c;" {
+		t.Error("cookies not equals:", s)
+	}
+	other, _ := url.Parse("https://www.other.com.cn/")
+	if s := cookieNames(jar.Cookies(other)); s != "" {
+		t.Error("public suffix cookies leaked:", s)
+	}
+
+	io, _ := url.Parse("https://foo.github.io/")
+	jar.SetCookies(io, []*http.Cookie{{Name: "io", Value: "e", Domain: "github.io"}})
+	bar, _ := url.Parse("https://bar.github.io/")
+	if s := cookieNames(jar.Cookies(bar)); s != "" {
+		t.Error("private suffix cookies leaked:", s)
+	}
+	if s := cookieNames(jar.Cookies(io)); s != "" {
+		t.Error("private suffix cookies not equals:", s)
+	}
+
+	uk, _ := url.Parse("https://www.bbc.co.uk/")
+	jar.SetCookies(uk, []*http.Cookie{{Name: "uk", Value: "d", Domain: ".co.uk"}})
+	if s := cookieNames(jar.Cookies(uk)); s != "" {
+		t.Error("public suffix cookies not equals:", s)
+	}
+}
+
+func TestFetchCookie(t *testing.T) {
+	var mu sync.Mutex
+	var received []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		received = append(received, r.Header.Get("Cookie"))
+		mu.Unlock()
+		http.SetCookie(w, &http.Cookie{Name: "sid", Value: "Ka7Guj", Path: "/", MaxAge: 300})
+		http.ServeFile(w, r, "./testdata/v2ex/tech.html")
+	}))
+	defer ts.Close()
+
+	f, _ := New("./rule/v2ex.yaml")
+	f.Config["v2ex"].Base = ts.URL
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := f.Index("v2ex")
+			if err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	res, err := f.Index("v2ex")
+	if err != nil || res == nil {
+		t.Error(res, err)
+		return
+	}
+	if last := received[len(received)-1]; last != "sid=Ka7Guj" {
+		t.Error("cookie not sent:", fmt.Sprint(received))
+	}
+	if l := len(f.CookieJar("v2ex").All()); l != 1 {
+		t.Error("cookie len not equals 1:", l)
+	}
+}
@@ -0,0 +1,117 @@
+package gofetch
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const httpOnlyPrefix = "#HttpOnly_"
+
+// ImportCookies 从 Netscape/Mozilla cookies.txt 格式导入站点的 cookie，
+// 只导入会发送到 Config.Base 的 cookie，返回导入的数量
+func (f *Fetch) ImportCookies(key string, r io.Reader) (int, error) {
+	config := f.config(key)
+	if config == nil {
+		return 0, ErrConfigNotFound
+	}
+	base, err := url.Parse(config.Base)
+	if err != nil {
+		return 0, err
+	}
+	host, err := canonicalHost(base.Host)
+	if err != nil {
+		return 0, err
+	}
+
+	cookies, err := parseCookiesTxt(r)
+	if err != nil {
+		return 0, err
+	}
+	var matched []*Cookie
+	now := time.Now()
+	for _, c := range cookies {
+		if c.domainMatch(host) && !c.expired(now) {
+			matched = append(matched, c)
+		}
+	}
+	f.CookieJar(key).Add(matched...)
+	f.saveSession(key, false)
+	return len(matched), nil
+}
+
+// ExportCookies 以 Netscape/Mozilla cookies.txt 格式导出站点的 cookie，可以直接给 curl 或 wget 使用
+func (f *Fetch) ExportCookies(key string, w io.Writer) error {
+	if f.config(key) == nil {
+		return ErrConfigNotFound
+	}
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "# Netscape HTTP Cookie File")
+	for _, c := range f.CookieJar(key).All() {
+		domain := c.Domain
+		subdomains := "FALSE"
+		if !c.HostOnly {
+			domain = "." + domain
+			subdomains = "TRUE"
+		}
+		if c.HttpOnly {
+			domain = httpOnlyPrefix + domain
+		}
+		secure := "FALSE"
+		if c.Secure {
+			secure = "TRUE"
+		}
+		var expires int64
+		if !c.Expires.IsZero() {
+			expires = c.Expires.Unix()
+		}
+		fmt.Fprintf(bw, "%s\t%s\t%s\t%s\t%d\t%s\t%s\n", domain, subdomains, c.Path, secure, expires, c.Name, c.Value)
+	}
+	return bw.Flush()
+}
+
+func parseCookiesTxt(r io.Reader) ([]*Cookie, error) {
+	var cookies []*Cookie
+	scanner := bufio.NewScanner(r)
+	no := 0
+	for scanner.Scan() {
+		no++
+		line := strings.TrimRight(scanner.Text(), "\r")
+		httpOnly := strings.HasPrefix(line, httpOnlyPrefix)
+		if httpOnly {
+			line = line[len(httpOnlyPrefix):]
+		}
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Split(line, "\t")
+		if len(fields) == 6 {
+			fields = append(fields, "")
+		}
+		if len(fields) != 7 {
+			return nil, fmt.Errorf("cookies.txt line %d: expected 7 fields, got %d", no, len(fields))
+		}
+		expires, err := strconv.ParseInt(fields[4], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("cookies.txt line %d: invalid expires %q", no, fields[4])
+		}
+		c := &Cookie{
+			Name:     fields[5],
+			Value:    fields[6],
+			Domain:   strings.TrimPrefix(strings.ToLower(fields[0]), "."),
+			Path:     fields[2],
+			Secure:   strings.EqualFold(fields[3], "TRUE"),
+			HttpOnly: httpOnly,
+			HostOnly: !strings.EqualFold(fields[1], "TRUE"),
+		}
+		if expires > 0 {
+			c.Expires = time.Unix(expires, 0)
+		}
+		cookies = append(cookies, c)
+	}
+	return cookies, scanner.Err()
+}
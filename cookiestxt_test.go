@@ -0,0 +1,58 @@
+package gofetch
+
+import (
+	"bytes"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+const testCookiesTxt = `# Netscape HTTP Cookie File
+# https://curl.se/docs/http-cookies.html
+
+.v2ex.com	TRUE	/	FALSE	0	PB3_SESSION	"2|1:0|10:1513"
+#HttpOnly_www.v2ex.com	FALSE	/	TRUE	4102444800	A2	"2|1:0|10:1514"
+.v2ex.com	TRUE	/	FALSE	946684800	old	expired
+.hi-pda.com	TRUE	/	FALSE	0	cdb_sid	Ka7Guj
+`
+
+func TestImportExportCookies(t *testing.T) {
+	f, _ := New("./rule/v2ex.yaml")
+	n, err := f.ImportCookies("v2ex", strings.NewReader(testCookiesTxt))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if n != 2 {
+		t.Error("imported not equals 2:", n)
+	}
+
+	u, _ := url.Parse("https://www.v2ex.com/t/416297")
+	s := cookieNames(f.CookieJar("v2ex").Cookies(u))
+	if s != `PB3_SESSION="2|1:0|10:1513";A2="2|1:0|10:1514";` {
+		t.Error("cookies not equals:", s)
+	}
+
+	var buf bytes.Buffer
+	err = f.ExportCookies("v2ex", &buf)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	one := `# Netscape HTTP Cookie File
+.v2ex.com	TRUE	/	FALSE	0	PB3_SESSION	"2|1:0|10:1513"
+#HttpOnly_www.v2ex.com	FALSE	/	TRUE	4102444800	A2	"2|1:0|10:1514"
+`
+	if buf.String() != one {
+		t.Error("export not equals:", buf.String())
+	}
+
+	_, err = f.ImportCookies("v2ex", strings.NewReader(".v2ex.com\tTRUE\t/\n"))
+	if err == nil {
+		t.Error("invalid line must fail")
+	}
+	_, err = f.ImportCookies("xxxx", strings.NewReader(testCookiesTxt))
+	if err == nil {
+		t.Error("config must not be found")
+	}
+}
@@ -0,0 +1,157 @@
+package gofetch
+
+import (
+	"context"
+	"iter"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Crawler 从规则的首页开始，沿着能匹配同一站点页面规则的链接依次获取页面
+//
+// 链接来自 Categories 和 Items 中名为 link 或以 Link 结尾的字段，以及分页的下一页地址，
+// 地址规范化后去重，form 类型的页面不会被获取
+type Crawler struct {
+	Fetch        *Fetch
+	MaxDepth     int            // 最大深度，首页为 0，小于 0 时不限制
+	Limits       map[string]int // 每种规则类型最多获取的页面数，如 {"thread": 100}，没有设置的类型不限制
+	IgnoreParams []string       // 规范化地址时去掉的查询参数，如 hi-pda 链接中的 extra
+}
+
+// CrawlRes 抓取到的页面
+type CrawlRes struct {
+	*Res
+	URL     string // 请求的地址
+	Type    string // 匹配的规则类型
+	Depth   int
+	Referer string // 发现该地址的页面，首页为空
+}
+
+// NewCrawler 创建抓取器，默认深度为 2，即首页、列表和帖子
+func NewCrawler(f *Fetch) *Crawler {
+	return &Crawler{Fetch: f, MaxDepth: 2}
+}
+
+type crawlTask struct {
+	url, referer string
+	depth        int
+	cr           *ConfigRule // 分页的下一页使用当前页的规则
+}
+
+// Crawl 从 key 对应规则的首页开始抓取，每获取一个页面返回一次，
+// 获取失败的页面返回错误后继续抓取其他页面，循环提前结束时停止抓取
+func (c *Crawler) Crawl(ctx context.Context, key string) iter.Seq2[*CrawlRes, error] {
+	return func(yield func(*CrawlRes, error) bool) {
+		config := c.Fetch.config(key)
+		if config == nil {
+			yield(nil, ErrConfigNotFound)
+			return
+		}
+		start := c.normalize(config.Base + config.Index.URL)
+		queue := []crawlTask{{url: start}}
+		seen := map[string]bool{start: true}
+		counts := make(map[string]int)
+		for len(queue) > 0 {
+			if err := ctx.Err(); err != nil {
+				yield(nil, err)
+				return
+			}
+			task := queue[0]
+			queue = queue[1:]
+			cr := task.cr
+			if cr == nil {
+				cr = c.Fetch.matchConfigRule(task.url)
+			}
+			if cr == nil || cr.Rule.Type == "form" {
+				continue
+			}
+			typ := cr.Rule.Type
+			if limit, ok := c.Limits[typ]; ok && counts[typ] >= limit {
+				continue
+			}
+			counts[typ]++
+
+			var res *Res
+			var err error
+			if task.cr != nil {
+				res, err = c.Fetch.fetchRes(ctx, cr, task.url, typ, nil)
+			} else {
+				res, err = c.Fetch.DataContext(ctx, task.url)
+			}
+			cres := &CrawlRes{res, task.url, typ, task.depth, task.referer}
+			if err != nil {
+				if !yield(cres, err) {
+					return
+				}
+				continue
+			}
+			if !yield(cres, nil) {
+				return
+			}
+
+			enqueue := func(link string, depth int, cr *ConfigRule) {
+				if link == "" || c.MaxDepth >= 0 && depth > c.MaxDepth {
+					return
+				}
+				link = c.normalize(link)
+				if seen[link] || !strings.HasPrefix(link, config.Base) {
+					return
+				}
+				seen[link] = true
+				queue = append(queue, crawlTask{link, task.url, depth, cr})
+			}
+			enqueue(res.Meta.Next, task.depth, cr)
+			for _, ms := range [][]map[string]string{res.Categories, res.Items} {
+				for _, m := range ms {
+					for _, k := range sortedKeys(m) {
+						if k == "link" || strings.HasSuffix(k, "Link") {
+							enqueue(m[k], task.depth+1, nil)
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+// normalize 规范化地址：scheme 和 host 转为小写，去掉默认端口、片段和 IgnoreParams 中的查询参数
+func (c *Crawler) normalize(ref string) string {
+	u, err := url.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	if port := u.Port(); u.Scheme == "http" && port == "80" || u.Scheme == "https" && port == "443" {
+		u.Host = u.Hostname()
+	}
+	u.Fragment = ""
+	u.RawFragment = ""
+	if len(c.IgnoreParams) > 0 && u.RawQuery != "" {
+		q := u.Query()
+		for _, p := range c.IgnoreParams {
+			q.Del(p)
+		}
+		// 保留原有参数的顺序，规则的 match 依赖参数的顺序
+		var params []string
+		for _, kv := range strings.Split(u.RawQuery, "&") {
+			k, _, _ := strings.Cut(kv, "=")
+			if name, err := url.QueryUnescape(k); err == nil && q.Has(name) {
+				params = append(params, kv)
+			}
+		}
+		u.RawQuery = strings.Join(params, "&")
+	}
+	return u.String()
+}
+
+// sortedKeys 返回排序后的 key，保证抓取顺序稳定
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,115 @@
+package gofetch
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newHipdaServer(paths *[]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*paths = append(*paths, r.URL.RequestURI())
+		switch r.URL.Path {
+		case "/index.php":
+			http.ServeFile(w, r, "./testdata/hipda/index.html")
+		case "/forumdisplay.php":
+			http.ServeFile(w, r, "./testdata/hipda/forumdisplay.html")
+		case "/viewthread.php":
+			http.ServeFile(w, r, "./testdata/hipda/viewthread.html")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestCrawl(t *testing.T) {
+	var paths []string
+	ts := newHipdaServer(&paths)
+	defer ts.Close()
+
+	f, _ := New("./rule/hipda.yaml")
+	f.Config["hipda"].Base = ts.URL
+	c := NewCrawler(f)
+	c.Limits = map[string]int{"list": 2, "thread": 3}
+	c.IgnoreParams = []string{"extra"}
+
+	counts := make(map[string]int)
+	var results []*CrawlRes
+	for res, err := range c.Crawl(context.Background(), "hipda") {
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		counts[res.Type]++
+		results = append(results, res)
+	}
+	if counts["index"] != 1 || counts["list"] != 2 || counts["thread"] != 3 || len(paths) != 6 {
+		t.Error("counts not equals:", counts, paths)
+		return
+	}
+	index, list, thread := results[0], results[1], results[len(results)-1]
+	if index.URL != ts.URL+"/index.php" || index.Depth != 0 || index.Referer != "" || len(index.Items) == 0 {
+		t.Error("index not equals:", index.URL, index.Depth)
+	}
+	if list.URL != ts.URL+"/forumdisplay.php?fid=5" || list.Depth != 1 || list.Referer != index.URL {
+		t.Error("list not equals:", list.URL, list.Depth, list.Referer)
+	}
+	if thread.Depth != 2 || strings.Contains(thread.URL, "extra=") || thread.Referer != list.URL || len(thread.Items) == 0 {
+		t.Error("thread not equals:", thread.URL, thread.Depth, thread.Referer)
+	}
+}
+
+func TestCrawlDepth(t *testing.T) {
+	var paths []string
+	ts := newHipdaServer(&paths)
+	defer ts.Close()
+
+	f, _ := New("./rule/hipda.yaml")
+	f.Config["hipda"].Base = ts.URL
+	c := NewCrawler(f)
+	c.MaxDepth = 1
+	c.Limits = map[string]int{"list": 1}
+
+	var types []string
+	for res, err := range c.Crawl(context.Background(), "hipda") {
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		types = append(types, res.Type)
+	}
+	if strings.Join(types, ",") != "index,list" {
+		t.Error("types not equals:", types)
+	}
+
+	n := 0
+	for range c.Crawl(context.Background(), "hipda") {
+		n++
+		break
+	}
+	if n != 1 {
+		t.Error("crawl must stop")
+	}
+	for _, err := range c.Crawl(context.Background(), "xxxx") {
+		if err != ErrConfigNotFound {
+			t.Error("error not equals:", err)
+		}
+	}
+}
+
+func TestCrawlerNormalize(t *testing.T) {
+	c := &Crawler{IgnoreParams: []string{"extra"}}
+	tests := map[string]string{
+		"HTTPS://WWW.Hi-PDA.com:443/forum/viewthread.php?tid=1&extra=page%3D1#pid2": "https://www.hi-pda.com/forum/viewthread.php?tid=1",
+		"http://a.com:80/b?z=1&a=2": "http://a.com/b?z=1&a=2",
+		"http://a.com:8080/b#c":     "http://a.com:8080/b",
+		"http://a.com/b?extra=1":    "http://a.com/b",
+	}
+	for in, want := range tests {
+		if got := c.normalize(in); got != want {
+			t.Error(in, "not equals:", got, want)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package gofetch
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dateLayouts 支持的绝对时间格式，没有年份的格式使用当前年份
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05 -07:00",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05 MST",
+	time.RFC1123Z,
+	time.RFC1123,
+	"2006-1-2 15:04:05",
+	"2006-1-2 15:04",
+	"2006-1-2",
+	"2006/1/2 15:04:05",
+	"2006/1/2 15:04",
+	"2006/1/2",
+	"2006年1月2日 15:04:05",
+	"2006年1月2日 15:04",
+	"2006年1月2日",
+	"Jan 2, 2006 15:04",
+	"Jan 2, 2006",
+	"2 Jan 2006 15:04",
+	"2 Jan 2006",
+}
+
+var noYearLayouts = []string{
+	"1-2 15:04",
+	"1月2日 15:04",
+	"1月2日",
+	"Jan 2 15:04",
+	"Jan 2",
+}
+
+var (
+	dayRe      = regexp.MustCompile(`^(今天|昨天|前天|today|yesterday)\s*(\d{1,2}:\d{2}(?::\d{2})?)?$`)
+	relativeRe = regexp.MustCompile(`(\d+|一|两|an?\s)\s*(?:个)?\s*(秒钟|秒|分钟|分|小时|钟头|天|日|周|星期|月|年|seconds?|secs?|minutes?|mins?|months?|hours?|hrs?|days?|weeks?|years?|s|m|h|d|w|y)`)
+	halfHourRe = regexp.MustCompile(`半个?小时|half an hour`)
+)
+
+// relativeUnits 相对时间的单位，月和年使用 AddDate
+var relativeUnits = map[string]time.Duration{
+	"秒": time.Second, "秒钟": time.Second, "second": time.Second, "sec": time.Second, "s": time.Second,
+	"分": time.Minute, "分钟": time.Minute, "minute": time.Minute, "min": time.Minute, "m": time.Minute,
+	"小时": time.Hour, "钟头": time.Hour, "hour": time.Hour, "hr": time.Hour, "h": time.Hour,
+	"天": 24 * time.Hour, "日": 24 * time.Hour, "day": 24 * time.Hour, "d": 24 * time.Hour,
+	"周": 7 * 24 * time.Hour, "星期": 7 * 24 * time.Hour, "week": 7 * 24 * time.Hour, "w": 7 * 24 * time.Hour,
+	"月": -1, "month": -1,
+	"年": -2, "year": -2, "y": -2,
+}
+
+// ParseDate 解析论坛中显示的时间，支持常见的绝对时间格式和中英文相对时间，
+// 如 "2018-3-31 10:02"、"刚刚"、"3 小时 12 分钟前"、"昨天 12:30"、"2 days ago"，
+// 相对时间基于 now 计算，没有时区的时间使用 loc，loc 为 nil 时使用 time.Local
+func ParseDate(s string, now time.Time, loc *time.Location) (time.Time, error) {
+	if loc == nil {
+		loc = time.Local
+	}
+	now = now.In(loc)
+	v := strings.Join(strings.Fields(strings.ReplaceAll(s, " ", " ")), " ")
+	for _, layout := range dateLayouts {
+		if t, err := time.ParseInLocation(layout, v, loc); err == nil {
+			return t, nil
+		}
+	}
+	for _, layout := range noYearLayouts {
+		if t, err := time.ParseInLocation(layout, v, loc); err == nil {
+			t = t.AddDate(now.Year()-t.Year(), 0, 0)
+			if t.After(now.AddDate(0, 0, 1)) {
+				t = t.AddDate(-1, 0, 0)
+			}
+			return t, nil
+		}
+	}
+
+	lv := strings.ToLower(v)
+	switch lv {
+	case "刚刚", "刚才", "just now", "now":
+		return now, nil
+	}
+	if m := dayRe.FindStringSubmatch(lv); m != nil {
+		t := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+		switch m[1] {
+		case "昨天", "yesterday":
+			t = t.AddDate(0, 0, -1)
+		case "前天":
+			t = t.AddDate(0, 0, -2)
+		}
+		if m[2] != "" {
+			clock := strings.Split(m[2], ":")
+			units := []time.Duration{time.Hour, time.Minute, time.Second}
+			for i, c := range clock {
+				n, _ := strconv.Atoi(c)
+				t = t.Add(time.Duration(n) * units[i])
+			}
+		}
+		return t, nil
+	}
+
+	if rest, ok := trimRelativeSuffix(lv); ok {
+		rest = halfHourRe.ReplaceAllString(rest, "30 分钟")
+		t := now
+		matched := false
+		end := 0 // 上一个相对时间的结束位置，用于支持 3h12m 这样连续的写法
+		for _, idx := range relativeRe.FindAllStringSubmatchIndex(rest, -1) {
+			if !relativeMatch(rest, idx, end) {
+				continue
+			}
+			end = idx[1]
+			m := []string{rest[idx[0]:idx[1]], strings.TrimSpace(rest[idx[2]:idx[3]]), rest[idx[4]:idx[5]]}
+			var n int
+			switch m[1] {
+			case "一", "a", "an":
+				n = 1
+			case "两":
+				n = 2
+			default:
+				n, _ = strconv.Atoi(m[1])
+			}
+			unit := strings.TrimSuffix(m[2], "s")
+			if _, ok := relativeUnits[unit]; !ok {
+				unit = m[2]
+			}
+			d := relativeUnits[unit]
+			switch {
+			case d == -1:
+				t = t.AddDate(0, -n, 0)
+			case d == -2:
+				t = t.AddDate(-n, 0, 0)
+			default:
+				t = t.Add(-time.Duration(n) * d)
+			}
+			matched = true
+		}
+		if matched {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unknown date format %q", s)
+}
+
+// relativeMatch 检查 relativeRe 的匹配是否为独立的词：数字前不能是字母或数字（紧跟上一个匹配时除外），
+// 英文单位后不能是字母，单字母的单位（如 3h）必须紧跟在数字后，避免匹配 admin、last 等单词中的字母
+func relativeMatch(s string, idx []int, prevEnd int) bool {
+	isLetter := func(c byte) bool {
+		return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+	}
+	if c := idx[0]; c > 0 && c != prevEnd && (isLetter(s[c-1]) || s[c-1] >= '0' && s[c-1] <= '9') {
+		return false
+	}
+	if idx[1] < len(s) && isLetter(s[idx[1]]) && isLetter(s[idx[1]-1]) {
+		return false
+	}
+	if idx[5]-idx[4] == 1 {
+		num := s[idx[2]:idx[3]]
+		return num[0] >= '0' && num[0] <= '9' && idx[3] == idx[4]
+	}
+	return true
+}
+
+// trimRelativeSuffix 去掉相对时间的 "前"、"ago" 后缀
+func trimRelativeSuffix(s string) (string, bool) {
+	for _, suffix := range []string{"以前", "之前", "前", "ago"} {
+		if strings.HasSuffix(s, suffix) {
+			return strings.TrimSpace(strings.TrimSuffix(s, suffix)), true
+		}
+	}
+	return s, false
+}
+
+// normalizeDates 将 m 中 date 类型的字段转换为 RFC 3339 格式，无法解析的值保持不变
+func normalizeDates(m map[string]string, types map[string]*FieldType, now time.Time, loc *time.Location) {
+	for name, ft := range types {
+		if ft == nil || ft.Type != "date" {
+			continue
+		}
+		v, ok := m[name]
+		if !ok || strings.TrimSpace(v) == "" {
+			continue
+		}
+		var t time.Time
+		var err error
+		if ft.Layout != "" {
+			t, err = time.ParseInLocation(ft.Layout, strings.TrimSpace(v), loc)
+		}
+		if ft.Layout == "" || err != nil {
+			t, err = ParseDate(v, now, loc)
+		}
+		if err == nil {
+			m[name] = t.Format(time.RFC3339)
+		}
+	}
+}
+
+// location 返回规则的时区，TimeZone 为空或无效时使用 time.Local
+func (config *Config) location() *time.Location {
+	if config.TimeZone == "" {
+		return time.Local
+	}
+	loc, err := time.LoadLocation(config.TimeZone)
+	if err != nil {
+		return time.Local
+	}
+	return loc
+}
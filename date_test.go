@@ -0,0 +1,84 @@
+package gofetch
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	loc := time.FixedZone("CST", 8*3600)
+	now := time.Date(2018, 3, 31, 15, 20, 30, 0, loc)
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{"2018-3-31 10:02", time.Date(2018, 3, 31, 10, 2, 0, 0, loc)},
+		{"2018-03-31 10:02:33 +08:00", time.Date(2018, 3, 31, 10, 2, 33, 0, loc)},
+		{"2017/12/1", time.Date(2017, 12, 1, 0, 0, 0, 0, loc)},
+		{"2018年3月1日 08:00", time.Date(2018, 3, 1, 8, 0, 0, 0, loc)},
+		{"3-30 22:15", time.Date(2018, 3, 30, 22, 15, 0, 0, loc)},
+		{"12-30 22:15", time.Date(2017, 12, 30, 22, 15, 0, 0, loc)},
+		{"刚刚", now},
+		{"just now", now},
+		{"3 小时 12 分钟前", now.Add(-3*time.Hour - 12*time.Minute)},
+		{"1 小时 0 分钟前", now.Add(-time.Hour)},
+		{"半小时前", now.Add(-30 * time.Minute)},
+		{"5 天前", now.AddDate(0, 0, -5)},
+		{"一个月前", now.AddDate(0, -1, 0)},
+		{"昨天 12:30", time.Date(2018, 3, 30, 12, 30, 0, 0, loc)},
+		{"前天 08:05:09", time.Date(2018, 3, 29, 8, 5, 9, 0, loc)},
+		{"今天", time.Date(2018, 3, 31, 0, 0, 0, 0, loc)},
+		{"2 days ago", now.AddDate(0, 0, -2)},
+		{"an hour ago", now.Add(-time.Hour)},
+		{"3 months ago", now.AddDate(0, -3, 0)},
+		{"5 mins ago", now.Add(-5 * time.Minute)},
+		{"3h ago", now.Add(-3 * time.Hour)},
+		{"1h30m ago", now.Add(-90 * time.Minute)},
+		// 单词中的字母不能作为数量或单位
+		{"admin 3 days ago", now.AddDate(0, 0, -3)},
+		{"last edited 3 days ago", now.AddDate(0, 0, -3)},
+		{"yesterday 09:00", time.Date(2018, 3, 30, 9, 0, 0, 0, loc)},
+	}
+	for _, tt := range tests {
+		got, err := ParseDate(tt.in, now, loc)
+		if err != nil || !got.Equal(tt.want) {
+			t.Error(tt.in, "not equals:", got, err)
+		}
+	}
+
+	for _, in := range []string{"", "明天", "some time ago", "2018-13-45", "admin ago", "last seen ago", "3 monkeys ago"} {
+		if _, err := ParseDate(in, now, loc); err == nil {
+			t.Error(in, "must fail")
+		}
+	}
+}
+
+func TestDataDate(t *testing.T) {
+	start := time.Now()
+	_, res, err := makeData(&testConfig{
+		"./testdata/fields/v2ex.yaml",
+		"./testdata/v2ex/tech.html",
+		"https://www.v2ex.com/?tab=tech",
+	}, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if res == nil || len(res.Items) == 0 {
+		t.Error("items not equals:", res)
+		return
+	}
+	posted := res.Items[0]["posted"]
+	v, err := time.Parse(time.RFC3339, posted)
+	if err != nil || v.Format("-07:00") != "+08:00" {
+		t.Error("posted not equals:", posted, err)
+	}
+	want := start.Add(-2 * time.Minute)
+	if d := v.Sub(want); d < -time.Second || d > time.Since(start)+time.Second {
+		t.Error("posted not equals:", v, want)
+	}
+	pt, err := res.ItemTime(0, "posted")
+	if err != nil || !pt.Equal(v) {
+		t.Error("typed posted not equals:", pt, err)
+	}
+}
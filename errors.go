@@ -0,0 +1,102 @@
+package gofetch
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	// ErrConfigNotFound key 对应的规则不存在
+	ErrConfigNotFound = errors.New("config not found")
+	// ErrNoRuleMatched URL 没有匹配的规则
+	ErrNoRuleMatched = errors.New("no rule matched")
+	// ErrNoLoginForm 登录页面没有解析出登录表单
+	ErrNoLoginForm = errors.New("no login form")
+	// ErrInvalidJSON json 格式的规则获取到的内容不是有效的 JSON
+	ErrInvalidJSON = errors.New("invalid JSON response")
+	// ErrNotCached 离线模式下页面没有缓存
+	ErrNotCached = errors.New("not cached")
+)
+
+// maxErrorBody 错误中保存的响应内容的最大字节数
+const maxErrorBody = 512
+
+// HTTPStatusError 响应状态码不符合要求
+type HTTPStatusError struct {
+	URL        string
+	StatusCode int
+	Body       string
+}
+
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("%s: unexpected status code %d", e.URL, e.StatusCode)
+}
+
+// RobotsDisallowedError 站点的 robots.txt 不允许访问该地址
+type RobotsDisallowedError struct {
+	URL       string
+	UserAgent string
+}
+
+func (e *RobotsDisallowedError) Error() string {
+	return fmt.Sprintf("%s: disallowed by robots.txt for %s", e.URL, e.UserAgent)
+}
+
+// LoginFailedError 登录失败，Body 为截断后的响应内容
+type LoginFailedError struct {
+	Key        string
+	StatusCode int
+	Body       string
+}
+
+func (e *LoginFailedError) Error() string {
+	return fmt.Sprintf("%s: login failed, status code %d: %s", e.Key, e.StatusCode, e.Body)
+}
+
+// ConfigError 规则文件中的错误，Line 为 0 时表示无法定位到行
+type ConfigError struct {
+	File string
+	Line int
+	Msg  string
+}
+
+func (e *ConfigError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Msg)
+	}
+	return fmt.Sprintf("%s: %s", e.File, e.Msg)
+}
+
+// ConfigErrors 加载规则文件时的全部错误
+type ConfigErrors []*ConfigError
+
+func (e ConfigErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Unwrap 支持 errors.As 获取其中的 *ConfigError
+func (e ConfigErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+	return errs
+}
+
+// truncateBody 截断响应内容，避免错误信息过长
+func truncateBody(body []byte) string {
+	if len(body) <= maxErrorBody {
+		return string(body)
+	}
+	n := maxErrorBody
+	for n > 0 && !utf8.RuneStart(body[n]) {
+		n--
+	}
+	return string(body[:n]) + "..."
+}
@@ -0,0 +1,69 @@
+package gofetch
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLoginFailedError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(strings.Repeat("密码错误", 200)))
+	}))
+	defer ts.Close()
+
+	f, _ := New("./rule/hipda.yaml")
+	f.Config["hipda"].Base = ts.URL
+	ok, err := f.Login("hipda", &LoginInfo{Username: "abc", Password: "def"})
+	if ok {
+		t.Error("login must be failed")
+	}
+	var lfe *LoginFailedError
+	if !errors.As(err, &lfe) {
+		t.Error("error not equals LoginFailedError:", err)
+		return
+	}
+	if lfe.Key != "hipda" || lfe.StatusCode != http.StatusOK {
+		t.Error("error not equals:", lfe.Key, lfe.StatusCode)
+	}
+	if len(lfe.Body) > maxErrorBody+3 || !strings.HasPrefix(lfe.Body, "密码错误") || !strings.HasSuffix(lfe.Body, "...") {
+		t.Error("body not truncated:", lfe.Body)
+	}
+	if f.IsLoggedIn("hipda") {
+		t.Error("logged in")
+	}
+
+	_, err = f.Login("xxxx", &LoginInfo{})
+	if !errors.Is(err, ErrConfigNotFound) {
+		t.Error("error not equals ErrConfigNotFound:", err)
+	}
+	_, err = f.CreateLoginInfo("xxxx")
+	if !errors.Is(err, ErrConfigNotFound) {
+		t.Error("error not equals ErrConfigNotFound:", err)
+	}
+}
+
+func TestCaptchaHTTPStatusError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/_captcha" {
+			http.NotFound(w, r)
+			return
+		}
+		http.ServeFile(w, r, "./testdata/v2ex/login.html")
+	}))
+	defer ts.Close()
+
+	f, _ := New("./rule/v2ex.yaml")
+	f.Config["v2ex"].Base = ts.URL
+	_, err := f.CreateLoginInfo("v2ex")
+	var hse *HTTPStatusError
+	if !errors.As(err, &hse) {
+		t.Error("error not equals HTTPStatusError:", err)
+		return
+	}
+	if hse.StatusCode != http.StatusNotFound || hse.URL != ts.URL+"/_captcha?once=71137" {
+		t.Error("error not equals:", hse)
+	}
+}
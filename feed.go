@@ -0,0 +1,203 @@
+package gofetch
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html/charset"
+)
+
+// feedDoc RSS 2.0、RSS 1.0 和 Atom 共用的解析结构
+type feedDoc struct {
+	XMLName xml.Name
+	Channel struct {
+		Title       string     `xml:"title"`
+		Links       []string   `xml:"link"`
+		Description string     `xml:"description"`
+		Items       []*rssItem `xml:"item"`
+	} `xml:"channel"`
+	Items []*rssItem `xml:"item"` // RSS 1.0 的 item 与 channel 同级
+
+	Title    string       `xml:"title"`
+	Subtitle string       `xml:"subtitle"`
+	Updated  string       `xml:"updated"`
+	Links    []*atomLink  `xml:"link"`
+	Entries  []*atomEntry `xml:"entry"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Links       []string `xml:"link"`
+	Description string   `xml:"description"`
+	Author      string   `xml:"author"`
+	Creator     string   `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	PubDate     string   `xml:"pubDate"`
+	Date        string   `xml:"http://purl.org/dc/elements/1.1/ date"`
+	GUID        string   `xml:"guid"`
+	Categories  []string `xml:"category"`
+	Content     string   `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	Comments    string   `xml:"http://purl.org/rss/1.0/modules/slash/ comments"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+}
+
+type atomEntry struct {
+	Title     string      `xml:"title"`
+	Links     []*atomLink `xml:"link"`
+	ID        string      `xml:"id"`
+	Published string      `xml:"published"`
+	Updated   string      `xml:"updated"`
+	Author    struct {
+		Name string `xml:"name"`
+		URI  string `xml:"uri"`
+	} `xml:"author"`
+	Summary    string `xml:"summary"`
+	Content    string `xml:"content"`
+	Categories []struct {
+		Term string `xml:"term,attr"`
+	} `xml:"category"`
+	Total string `xml:"http://purl.org/syndication/thread/1.0 total"`
+}
+
+// parseFeed 解析 RSS 或 Atom，每个条目转换为与 parseList 相同字段的 item，
+// 另外包含 id、published、updated、summary、content、category 字段，时间转换为 RFC 3339 格式
+func parseFeed(base *url.URL, resp *http.Response, add itemFunc) (*Res, error) {
+	dec := xml.NewDecoder(resp.Body)
+	dec.Strict = false
+	dec.CharsetReader = charset.NewReaderLabel
+	var doc feedDoc
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	res := &Res{Content: make(map[string]string)}
+	if doc.XMLName.Local == "feed" {
+		res.Content["title"] = strings.TrimSpace(doc.Title)
+		res.Content["link"] = resolveLink(base, atomHref(doc.Links))
+		res.Content["desc"] = strings.TrimSpace(doc.Subtitle)
+		res.Content["updated"] = feedDate(doc.Updated)
+		for _, e := range doc.Entries {
+			categories := make([]string, 0, len(e.Categories))
+			for _, c := range e.Categories {
+				categories = append(categories, c.Term)
+			}
+			item := feedItem(base, map[string]string{
+				"title":      e.Title,
+				"link":       atomHref(e.Links),
+				"author":     e.Author.Name,
+				"authorLink": e.Author.URI,
+				"replyCount": e.Total,
+				"id":         e.ID,
+				"published":  e.Published,
+				"updated":    e.Updated,
+				"summary":    e.Summary,
+				"content":    e.Content,
+				"category":   strings.Join(categories, ","),
+			})
+			if !add(item) {
+				break
+			}
+		}
+		return res, nil
+	}
+
+	res.Content["title"] = strings.TrimSpace(doc.Channel.Title)
+	res.Content["link"] = resolveLink(base, firstText(doc.Channel.Links))
+	res.Content["desc"] = strings.TrimSpace(doc.Channel.Description)
+	for _, item := range append(doc.Channel.Items, doc.Items...) {
+		author := item.Creator
+		if author == "" {
+			author = item.Author
+		}
+		published := item.PubDate
+		if published == "" {
+			published = item.Date
+		}
+		if !add(feedItem(base, map[string]string{
+			"title":      item.Title,
+			"link":       firstText(item.Links),
+			"author":     author,
+			"replyCount": item.Comments,
+			"id":         item.GUID,
+			"published":  published,
+			"updated":    published,
+			"summary":    item.Description,
+			"content":    item.Content,
+			"category":   strings.Join(item.Categories, ","),
+		})) {
+			break
+		}
+	}
+	return res, nil
+}
+
+// feedItem 补全 parseList 中的字段，转换链接和时间
+func feedItem(base *url.URL, m map[string]string) map[string]string {
+	item := map[string]string{
+		"avatar":        "",
+		"authorLink":    "",
+		"lastReply":     "",
+		"lastReplyLink": "",
+	}
+	for k, v := range m {
+		item[k] = strings.TrimSpace(v)
+	}
+	item["link"] = resolveLink(base, item["link"])
+	item["authorLink"] = resolveLink(base, item["authorLink"])
+	item["published"] = feedDate(item["published"])
+	item["updated"] = feedDate(item["updated"])
+	return item
+}
+
+// feedDate 将 RSS 和 Atom 中的时间转换为 RFC 3339 格式，无法解析时保持不变
+func feedDate(s string) string {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return ""
+	}
+	t, err := ParseDate(s, time.Now(), time.UTC)
+	if err != nil {
+		return s
+	}
+	return t.Format(time.RFC3339)
+}
+
+// atomHref 返回 rel 为 alternate 或为空的链接
+func atomHref(links []*atomLink) string {
+	for _, link := range links {
+		if link.Rel == "" || link.Rel == "alternate" {
+			return link.Href
+		}
+	}
+	return ""
+}
+
+// firstText 返回第一个不为空的值，用于跳过 RSS 中的 atom:link
+func firstText(vs []string) string {
+	for _, v := range vs {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// expandURL 按 match 中的分组展开地址模板 tmpl，相对地址使用 base 补全
+func (r *Rule) expandURL(base, ref, tmpl string) string {
+	re, err := regexp.Compile("^" + r.Match + "$")
+	if err != nil {
+		return base + tmpl
+	}
+	u := re.ReplaceAllString(strings.TrimPrefix(ref, base), tmpl)
+	if strings.Contains(u, "://") {
+		return u
+	}
+	return base + u
+}
@@ -0,0 +1,123 @@
+package gofetch
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDataFeedAtom(t *testing.T) {
+	_, res, err := makeData(&testConfig{
+		"./testdata/feed/v2ex.yaml",
+		"./testdata/feed/tech.xml",
+		"https://www.v2ex.com/feed/tech.xml",
+	}, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if res == nil || len(res.Items) != 2 {
+		t.Error("items not equals:", res)
+		return
+	}
+	if res.Content["title"] != "V2EX - 技术" || res.Content["link"] != "https://www.v2ex.com/" {
+		t.Error("content not equals:", res.Content)
+	}
+	item := res.Items[0]
+	if item["title"] != "年会被耍了 感觉很没意思 所以接下来该干啥呢" ||
+		item["link"] != res.Meta.URL[:len(res.Meta.URL)-len("/feed/tech.xml")]+"/t/416297#reply214" ||
+		item["author"] != "MrFireAwayH" ||
+		item["authorLink"] != "https://www.v2ex.com/member/MrFireAwayH" ||
+		item["replyCount"] != "214" ||
+		item["published"] != "2017-12-25T04:01:03Z" ||
+		item["category"] != "programmer" ||
+		item["content"] != "<p>年会</p>" {
+		t.Error("item not equals:", item)
+	}
+	for _, k := range []string{"avatar", "lastReply", "lastReplyLink"} {
+		if v, ok := item[k]; !ok || v != "" {
+			t.Error(k, "must be empty:", item)
+		}
+	}
+	if res.Meta.Type != "feed" {
+		t.Error("type not equals:", res.Meta.Type)
+	}
+}
+
+func TestDataFeedRSS(t *testing.T) {
+	_, res, err := makeData(&testConfig{
+		"./testdata/feed/v2ex.yaml",
+		"./testdata/feed/hipda.xml",
+		"https://www.v2ex.com/rss.php?fid=2",
+	}, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if res == nil || len(res.Items) != 1 {
+		t.Error("items not equals:", res)
+		return
+	}
+	if res.Content["link"] != "https://www.hi-pda.com/forum/forumdisplay.php?fid=2" {
+		t.Error("content not equals:", res.Content)
+	}
+	item := res.Items[0]
+	if item["title"] != "二手 iPhone 求推荐" ||
+		item["author"] != "alex" ||
+		item["replyCount"] != "12" ||
+		item["published"] != "2018-03-31T02:02:00Z" ||
+		item["summary"] != "内容" {
+		t.Error("item not equals:", item)
+	}
+}
+
+func TestDataFeedFallback(t *testing.T) {
+	var paths []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.RequestURI())
+		if r.URL.Path == "/feed/tab/tech.xml" {
+			http.ServeFile(w, r, "./testdata/feed/tech.xml")
+			return
+		}
+		http.ServeFile(w, r, "./testdata/v2ex/tech.html")
+	}))
+	defer ts.Close()
+
+	f, err := New("./testdata/feed/v2ex.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Config["v2ex"].Base = ts.URL
+	res, err := f.Data(ts.URL + "/?tab=tech")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(paths) != 2 || paths[1] != "/feed/tab/tech.xml" {
+		t.Error("paths not equals:", paths)
+	}
+	if len(res.Items) != 2 || res.Meta.Type != "feed" || res.Meta.URL != ts.URL+"/feed/tab/tech.xml" {
+		t.Error("res not equals:", res.Meta, res.Items)
+		return
+	}
+	if n, err := res.ItemInt(0, "replyCount"); n != 214 || err != nil {
+		t.Error("replyCount not equals:", n, err)
+	}
+}
+
+func TestFeedValidate(t *testing.T) {
+	_, errs := parseConfig("feed.yaml", []byte(`key: a
+base: https://a.com
+rules:
+  - type: feed
+    format: json
+    match: /feed
+  - type: feed
+    match: /rss
+    fields:
+      - name: a
+`))
+	if len(errs) != 2 {
+		t.Error("errors not equals:", errs)
+	}
+}
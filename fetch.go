@@ -1,9 +1,10 @@
 package gofetch
 
 import (
-	"crypto/md5"
-	"encoding/hex"
+	"context"
 	"errors"
+	"io"
+	"io/fs"
 	"io/ioutil"
 	"log"
 	"net/http"
@@ -11,10 +12,13 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/PuerkitoBio/goquery"
+	"github.com/tidwall/gjson"
 	"golang.org/x/net/html/charset"
-	yaml "gopkg.in/yaml.v2"
 )
 
 // Config 规则信息
@@ -35,13 +39,47 @@ type Config struct {
 		Replace map[string][]string
 		Convert map[string][][]string
 	}
-	Rules []*map[string]string
+	Headers      map[string][]string // 所有请求使用的请求头，值可以使用 {url}、{base} 等模板
+	UserAgents   []string            `yaml:"userAgents"` // 每次请求依次使用其中的 User-Agent
+	Client       *ClientConfig
+	RateLimit    *RateLimit `yaml:"rateLimit"`
+	Retry        *Retry     `yaml:"retry"`
+	IgnoreRobots bool       `yaml:"ignoreRobots"` // 不检查 robots.txt，用于已获得授权的站点
+	Status       []int
+	TimeZone     string `yaml:"timezone"` // date 类型字段使用的时区，如 Asia/Shanghai，为空时使用本地时区
+	Rules        []*Rule
+
+	source  string // 规则文件路径
+	keyLine int
+	fsys    fs.FS // 为 nil 时规则来自本地文件
+	bundled bool  // 内置的默认规则，可以被同 key 的规则覆盖
+	uaNext  atomic.Uint64
+}
+
+// Rule 页面解析规则，除 type、match 等配置外的字段都是选择器，以 xpath: 开头的选择器为 XPath 表达式
+//
+// Fields 为每个 item 额外提取的字段，Content 为页面额外提取的字段，
+// type 不是内置类型时只按 Fields 和 Content 提取数据
+type Rule struct {
+	Type       string
+	Match      string
+	Pagination *Pagination
+	Feed       string // list 规则没有解析出 item 时使用的 RSS 或 Atom 地址，可以使用 $1 引用 match 中的分组
+	Format     string // 内容格式，html（默认）或 json，type 为 json 时默认为 json，json 格式的选择器为 gjson 路径
+	Status     []int
+	CacheTTL   time.Duration       `yaml:"cacheTTL"` // 启用缓存时直接使用缓存的时间，超过后发送条件请求验证
+	Headers    map[string][]string // 匹配该规则的请求使用的请求头，覆盖 Config 中的同名请求头
+	Fields     []*Field
+	Content    []*Field
+	Convert    map[string][][]string // 按输出的字段名对 Content 和 Items 执行的转换规则
+	Types      map[string]*FieldType // 按输出的字段名声明的值类型
+	Selectors  map[string]string     `yaml:",inline"`
 }
 
 // ConfigRule 匹配URL对应的规则
 type ConfigRule struct {
 	Config  *Config
-	Rule    *map[string]string
+	Rule    *Rule
 	IsIndex bool
 }
 
@@ -50,6 +88,19 @@ type Res struct {
 	Content map[string]string
 	Categories,
 	Items []map[string]string
+	Meta  Meta
+	Types map[string]*FieldType // 字段声明的值类型，通过 Typed、ItemValue 等方法获取转换后的值
+}
+
+// Meta 响应信息
+type Meta struct {
+	URL        string // 重定向后的最终地址
+	StatusCode int
+	Header     http.Header
+	Type,
+	Match string
+	Next string // 分页规则中下一页的地址，没有下一页时为空
+	Page int    // 通过 Pages 获取时的页码
 }
 
 // LoginInfo 登录信息
@@ -62,56 +113,149 @@ type LoginInfo struct {
 	Ext      map[string]string
 }
 
-// Fetch 数据获取实例
+// Fetch 数据获取实例，可以在多个 goroutine 中共享
 type Fetch struct {
 	Config map[string]*Config
-	Cookie map[string][]*http.Cookie
+	Cookie map[string]*CookieJar
+
+	mu         sync.RWMutex
+	strict     bool
+	sources    []configSource
+	files      map[string]*watchedFile
+	reloadMu   sync.Mutex
+	client     *http.Client
+	clientOpts []func(*http.Client)
+	proxy      string
+	clients    map[string]*http.Client
+	limiters   map[string]*limiter
+	retry      *Retry
+	cache      *responseCache
+	offline    bool
+	loggedIn   map[string]bool
+
+	robotsAgent string
+	robotsMu    sync.Mutex
+	robotsCache map[string]*robotsEntry
+
+	sessions  SessionStore
+	sessionMu sync.Mutex
+	saved     map[string]uint64
 }
 
 // New 创建数据获取实例
 func New(configPaths ...string) (*Fetch, error) {
+	return NewWithOptions(configPaths)
+}
+
+// NewWithOptions 使用可选配置创建数据获取实例
+func NewWithOptions(configPaths []string, opts ...Option) (*Fetch, error) {
 	fetch := &Fetch{
-		make(map[string]*Config),
-		make(map[string][]*http.Cookie),
+		Config:      make(map[string]*Config),
+		Cookie:      make(map[string]*CookieJar),
+		client:      &http.Client{},
+		clients:     make(map[string]*http.Client),
+		limiters:    make(map[string]*limiter),
+		loggedIn:    make(map[string]bool),
+		robotsCache: make(map[string]*robotsEntry),
+		saved:       make(map[string]uint64),
+		files:       make(map[string]*watchedFile),
 	}
-	// configPaths = append([]string{
-	// 	"./rule/v2ex.yaml",
-	// 	"./rule/hipda.yaml",
-	// }, configPaths...)
-
-	for _, configPath := range configPaths {
-		config := &Config{}
-		content, err := ioutil.ReadFile(configPath)
-		if err != nil {
-			log.Println(err)
-			continue
+	for _, opt := range opts {
+		if err := opt(fetch); err != nil {
+			return nil, err
 		}
+	}
+	if err := fetch.applyClientOptions(); err != nil {
+		return nil, err
+	}
+	if fetch.offline && fetch.cache == nil {
+		return nil, errors.New("offline mode requires WithCache")
+	}
+	var errs ConfigErrors
+	for _, src := range fetch.sources {
+		errs = append(errs, fetch.loadConfigs(src.fsys, src.patterns, src.bundled)...)
+	}
+	errs = append(errs, fetch.loadConfigs(nil, configPaths, false)...)
+	if fetch.strict && len(errs) > 0 {
+		return nil, errs
+	}
+	fetch.loadSessions()
 
-		err = yaml.Unmarshal(content, config)
-		if err != nil {
-			log.Println(err)
-			continue
-		}
+	return fetch, nil
+}
 
-		if config.Key != "" {
-			fetch.Config[config.Key] = config
+// setConfig 保存规则，并创建站点对应的 cookie 存储和 http.Client，调用时需要持有写锁
+func (f *Fetch) setConfig(config *Config) {
+	f.Config[config.Key] = config
+	jar, ok := f.Cookie[config.Key]
+	if !ok {
+		jar = NewCookieJar()
+		f.Cookie[config.Key] = jar
+	}
+	client := f.client
+	if config.Client != nil {
+		c, err := newConfigClient(f.client, config.Client)
+		if err != nil {
+			log.Println(config.Key, err)
+		} else {
+			client = c
 		}
 	}
+	c := *client
+	c.Jar = jar
+	f.clients[config.Key] = &c
+	f.setLimiter(config)
+	f.resetRobots(config)
+}
 
-	return fetch, nil
+// config 获取 key 对应的规则
+func (f *Fetch) config(key string) *Config {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.Config[key]
+}
+
+// matchConfigRule 获取 URL 对应的规则
+func (f *Fetch) matchConfigRule(ref string) *ConfigRule {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return matchConfigRule(ref, f.Config)
+}
+
+// CookieJar 获取站点对应的 cookie 存储
+func (f *Fetch) CookieJar(key string) *CookieJar {
+	f.mu.RLock()
+	jar, ok := f.Cookie[key]
+	f.mu.RUnlock()
+	if ok {
+		return jar
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	jar, ok = f.Cookie[key]
+	if !ok {
+		jar = NewCookieJar()
+		f.Cookie[key] = jar
+	}
+	return jar
 }
 
 // CreateLoginInfo 获取登录必须的数据
 func (f *Fetch) CreateLoginInfo(key string) (*LoginInfo, error) {
-	v := f.Config[key]
+	return f.CreateLoginInfoContext(context.Background(), key)
+}
+
+// CreateLoginInfoContext 获取登录必须的数据，ctx 取消后停止请求
+func (f *Fetch) CreateLoginInfoContext(ctx context.Context, key string) (*LoginInfo, error) {
+	v := f.config(key)
 	if v != nil {
 		dataURL := v.Base + v.Login.URL
-		r, err := f.Data(dataURL)
+		r, err := f.DataContext(ctx, dataURL)
 		if err != nil {
 			return nil, err
 		}
-		if r == nil {
-			return nil, errors.New("no login form")
+		if r == nil || r.Content == nil {
+			return nil, ErrNoLoginForm
 		}
 
 		// mapDelAndGet := func(m map[string]string, k string) string {
@@ -142,21 +286,19 @@ func (f *Fetch) CreateLoginInfo(key string) (*LoginInfo, error) {
 				}
 				iu = base.ResolveReference(link).String()
 			}
-			req, err := http.NewRequest("GET", iu, nil)
+			req, err := http.NewRequestWithContext(ctx, "GET", iu, nil)
 			if err != nil {
 				return nil, err
 			}
-			cs := f.Cookie[v.Key]
-			for _, c := range cs {
-				req.AddCookie(c)
-			}
-			resp, err := http.DefaultClient.Do(req)
+			resp, err := f.do(v, nil, req)
 			if err != nil {
 				return nil, err
 			}
 			defer resp.Body.Close()
-
-			f.Cookie[v.Key] = updateCookies(resp.Cookies(), cs)
+			if resp.StatusCode < 200 || resp.StatusCode > 299 {
+				body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, maxErrorBody+1))
+				return nil, &HTTPStatusError{iu, resp.StatusCode, truncateBody(body)}
+			}
 
 			image, err = ioutil.ReadAll(resp.Body)
 			if err != nil {
@@ -172,14 +314,19 @@ func (f *Fetch) CreateLoginInfo(key string) (*LoginInfo, error) {
 			Ext:      r.Content,
 		}, nil
 	}
-	return nil, nil
+	return nil, ErrConfigNotFound
 }
 
 // Login 执行登录流程
 func (f *Fetch) Login(key string, li *LoginInfo) (bool, error) {
-	config, ok := f.Config[key]
-	if !ok || config == nil {
-		return false, errors.New("config not found")
+	return f.LoginContext(context.Background(), key, li)
+}
+
+// LoginContext 执行登录流程，ctx 取消后停止请求
+func (f *Fetch) LoginContext(ctx context.Context, key string, li *LoginInfo) (bool, error) {
+	config := f.config(key)
+	if config == nil {
+		return false, ErrConfigNotFound
 	}
 	if li.Username == "" {
 		return false, errors.New("username is empty")
@@ -224,7 +371,7 @@ func (f *Fetch) Login(key string, li *LoginInfo) (bool, error) {
 		data[k] = []string{v}
 	}
 
-	req, err := http.NewRequest("POST", config.Base+URL, strings.NewReader(data.Encode()))
+	req, err := http.NewRequestWithContext(ctx, "POST", config.Base+URL, strings.NewReader(data.Encode()))
 	if err != nil {
 		return false, err
 	}
@@ -237,25 +384,18 @@ func (f *Fetch) Login(key string, li *LoginInfo) (bool, error) {
 		}
 	}
 
-	cs := f.Cookie[key]
-	for _, c := range cs {
-		req.AddCookie(c)
-	}
-
 	// requestDump, err := httputil.DumpRequest(req, true)
 	// if err != nil {
 	// 	fmt.Println(err)
 	// }
 	// fmt.Println(string(requestDump))
 	// resp, err := http.DefaultClient.Do(nil)
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := f.do(config, config.matchRule(config.Base+URL), req)
 	if err != nil {
 		return false, err
 	}
 	defer resp.Body.Close()
 
-	cs = updateCookies(resp.Cookies(), cs)
-
 	contentType := resp.Header.Get("Content-Type")
 	r, err := charset.NewReader(resp.Body, contentType)
 	if err != nil {
@@ -269,112 +409,226 @@ func (f *Fetch) Login(key string, li *LoginInfo) (bool, error) {
 	if config.Login.CheckLogin != "" {
 		sb := string(body)
 		if strings.Contains(sb, config.Login.CheckLogin) {
+			f.setLoggedIn(key, true)
 			return true, nil
 		}
-		return false, errors.New(sb)
+		f.setLoggedIn(key, false)
+		return false, &LoginFailedError{key, resp.StatusCode, truncateBody(body)}
+	}
+	if !acceptStatus(resp.StatusCode, config.Status) {
+		f.setLoggedIn(key, false)
+		return false, &LoginFailedError{key, resp.StatusCode, truncateBody(body)}
 	}
+	f.setLoggedIn(key, true)
 	return true, nil
 }
 
 // Index 获取入口数据
 func (f *Fetch) Index(key string) (*Res, error) {
-	v := f.Config[key]
+	return f.IndexContext(context.Background(), key)
+}
+
+// IndexContext 获取入口数据，ctx 取消后停止请求和解析
+func (f *Fetch) IndexContext(ctx context.Context, key string) (*Res, error) {
+	v := f.config(key)
 	if v != nil {
-		return f.Data(v.Base + v.Index.URL)
+		return f.DataContext(ctx, v.Base+v.Index.URL)
 	}
-	return nil, nil
+	return nil, ErrConfigNotFound
 }
 
 // Data 获取指定URL数据
 func (f *Fetch) Data(ref string) (*Res, error) {
-	cr := matchConfigRule(ref, f.Config)
-	if cr != nil {
-		cs := f.Cookie[cr.Config.Key]
-		// client := &http.Client{}
-		req, err := http.NewRequest("GET", ref, nil)
-		// req.Header.Add("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_13_2) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/62.0.3202.89 Safari/537.36")
-		// req.Header.Add("Cookie", "cdb_onlineusernum=2658; cdb_sid=Ka7Guj;")
-		for _, c := range cs {
-			req.AddCookie(c)
-		}
-		resp, err := http.DefaultClient.Do(req)
-		// resp, err := http.Get(ref)
-		if err != nil {
-			return nil, err
-		}
-		defer resp.Body.Close()
+	return f.DataContext(context.Background(), ref)
+}
 
-		f.Cookie[cr.Config.Key] = updateCookies(resp.Cookies(), cs)
+// DataContext 获取指定URL数据，ctx 取消后停止请求和解析
+//
+// 规则设置了 Feed 且没有解析出 item 时，改为获取对应的 RSS 或 Atom 并按 feed 类型解析
+func (f *Fetch) DataContext(ctx context.Context, ref string) (*Res, error) {
+	cr := f.matchConfigRule(ref)
+	if cr == nil {
+		return nil, ErrNoRuleMatched
+	}
+	res, err := f.fetchRes(ctx, cr, ref, cr.Rule.Type, nil)
+	if err == nil && len(res.Items) == 0 && cr.Rule.Feed != "" {
+		return f.fetchRes(ctx, cr, cr.Rule.expandURL(cr.Config.Base, ref, cr.Rule.Feed), "feed", nil)
+	}
+	return res, err
+}
 
-		contentType := resp.Header.Get("Content-Type")
-		r, err := charset.NewReader(resp.Body, contentType)
-		if err != nil {
-			return nil, err
-		}
-		doc, err := goquery.NewDocumentFromReader(r)
-		// doc, err := goquery.NewDocumentFromResponse(resp)
-		if err != nil {
-			return nil, err
+// itemFunc 每解析出一个 item 调用一次，返回 false 时停止解析
+type itemFunc func(item map[string]string) bool
+
+// fetchRes 获取 ref 的内容并按 typ 类型解析
+//
+// yield 为 nil 时 item 保存在 Res.Items 中，否则每个 item 转换后交给 yield 而不再保存，
+// yield 返回 false 时停止解析并返回已解析的结果
+func (f *Fetch) fetchRes(ctx context.Context, cr *ConfigRule, ref, typ string, yield itemFunc) (*Res, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", ref, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := f.doCache(cr, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	status := cr.Rule.Status
+	if len(status) == 0 {
+		status = cr.Config.Status
+	}
+	if !acceptStatus(resp.StatusCode, status) {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, maxErrorBody+1))
+		return nil, &HTTPStatusError{resp.Request.URL.String(), resp.StatusCode, truncateBody(body)}
+	}
+
+	base, err := url.Parse(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []map[string]string
+	types := cr.Rule.types()
+	now, loc := time.Now(), cr.Config.location()
+	add := func(item map[string]string) bool {
+		convertMap(item, cr.Rule.Convert)
+		normalizeDates(item, types, now, loc)
+		if yield != nil {
+			return yield(item) && ctx.Err() == nil
 		}
+		items = append(items, item)
+		return ctx.Err() == nil
+	}
 
-		base, err := url.Parse(ref)
-		if err != nil {
-			return nil, err
+	var res *Res
+	switch {
+	case typ == "feed":
+		res, err = parseFeed(base, resp, add)
+	case cr.Rule.format() == "json":
+		var body []byte
+		body, err = ioutil.ReadAll(resp.Body)
+		if err == nil && !gjson.ValidBytes(body) {
+			err = ErrInvalidJSON
 		}
+		if err == nil {
+			res = parseJSON(base, cr.Rule, gjson.ParseBytes(body), add)
+		}
+	default:
+		res, err = parseHTML(ctx, base, cr, resp, add)
+	}
+	if err != nil {
+		return nil, err
+	}
+	res.Items = append(res.Items, items...)
+	convertMap(res.Content, cr.Rule.Convert)
+	normalizeDates(res.Content, types, now, loc)
+	res.Types = types
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	res.Meta.URL = resp.Request.URL.String()
+	res.Meta.StatusCode = resp.StatusCode
+	res.Meta.Header = resp.Header
+	res.Meta.Type = typ
+	res.Meta.Match = cr.Rule.Match
+	return res, nil
+}
 
-		var res *Res
-		switch (*cr.Rule)["type"] {
-		case "form":
-			res = parseForm(base, cr, doc)
-		case "index":
-			res = parseIndex(base, cr.Rule, doc)
-		case "list":
-			res = parseList(base, cr.Rule, doc)
-		case "thread":
-			res = parseThread(base, cr.Rule, doc)
+// parseHTML 按规则类型解析 HTML 内容
+func parseHTML(ctx context.Context, base *url.URL, cr *ConfigRule, resp *http.Response, add itemFunc) (*Res, error) {
+	contentType := resp.Header.Get("Content-Type")
+	r, err := charset.NewReader(resp.Body, contentType)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := goquery.NewDocumentFromReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var res *Res
+	switch cr.Rule.Type {
+	case "form":
+		res = parseForm(base, cr, doc)
+	case "index":
+		res = parseIndex(base, cr.Rule, doc, add)
+	case "list":
+		res = parseList(base, cr.Rule, doc, add)
+	case "thread":
+		res = parseThread(base, cr.Rule, doc, add)
+	default:
+		res = parseFields(base, cr.Rule, doc, add)
+	}
+	if len(cr.Rule.Content) > 0 {
+		if res.Content == nil {
+			res.Content = make(map[string]string)
 		}
+		extractFields(base, doc.Selection, cr.Rule.Content, res.Content)
+	}
 
-		if cr.IsIndex && cr.Config.Index.Category != nil {
-			rule := cr.Config.Index.Category
-			doc.Find(rule.Items).Each(func(i int, s *goquery.Selection) {
-				title := s.Text()
-				res.Categories = append(res.Categories, map[string]string{
-					"title": title,
-					"link":  getLink(base, s, "href"),
-				})
+	if cr.IsIndex && cr.Config.Index.Category != nil {
+		rule := cr.Config.Index.Category
+		find(doc.Selection, rule.Items).EachWithBreak(func(i int, s *goquery.Selection) bool {
+			title := s.Text()
+			res.Categories = append(res.Categories, map[string]string{
+				"title": title,
+				"link":  getLink(base, s, "href"),
 			})
-		}
-		return res, nil
+			return ctx.Err() == nil
+		})
+	}
+	if p := cr.Rule.Pagination; p != nil && p.Next != "" {
+		res.Meta.Next = getLink(base, find(doc.Selection, p.Next), "href")
 	}
-	return nil, nil
+	return res, nil
 }
 
 func matchConfigRule(url string, config map[string]*Config) *ConfigRule {
 	for _, v := range config {
-		if strings.HasPrefix(url, v.Base) {
-			url = url[len(v.Base):]
-			for _, r := range v.Rules {
-				match := (*r)["match"]
-				matched, err := regexp.MatchString("^"+match+"$", url)
-				if err != nil {
-					log.Println(err)
-				} else if matched {
-					return &ConfigRule{v, r, url == v.Index.URL}
-				}
-			}
+		if r := v.matchRule(url); r != nil {
+			return &ConfigRule{v, r, url[len(v.Base):] == v.Index.URL}
+		}
+	}
+	return nil
+}
+
+// matchRule 获取站点中 URL 对应的规则
+func (config *Config) matchRule(url string) *Rule {
+	if !strings.HasPrefix(url, config.Base) {
+		return nil
+	}
+	url = url[len(config.Base):]
+	for _, r := range config.Rules {
+		matched, err := regexp.MatchString("^"+r.Match+"$", url)
+		if err != nil {
+			log.Println(err)
+		} else if matched {
+			return r
 		}
 	}
 	return nil
 }
 
+// acceptStatus 判断状态码是否可以接受，accepted 为空时接受 2xx
+func acceptStatus(code int, accepted []int) bool {
+	if len(accepted) == 0 {
+		return code >= 200 && code <= 299
+	}
+	for _, c := range accepted {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
 func parseForm(base *url.URL, cr *ConfigRule, doc *goquery.Document) *Res {
 	res := &Res{Content: make(map[string]string)}
-	rule := *cr.Rule
-	for k, v := range rule {
-		if k == "type" || k == "match" {
-			continue
-		}
-		elem := doc.Find(v)
+	for k, v := range cr.Rule.Selectors {
+		elem := find(doc.Selection, v)
 		switch goquery.NodeName(elem) {
 		case "input":
 			// inputName, _ := elem.Attr("name")
@@ -413,15 +667,16 @@ func parseForm(base *url.URL, cr *ConfigRule, doc *goquery.Document) *Res {
 	return res
 }
 
-func parseIndex(base *url.URL, ruleMap *map[string]string, doc *goquery.Document) *Res {
+func parseIndex(base *url.URL, r *Rule, doc *goquery.Document, add itemFunc) *Res {
 	res := &Res{}
-	rule := *ruleMap
+	rule := r.Selectors
 	cat := rule["categories"]
 	catTitle := rule["categoryTitle"]
-	doc.Find(cat).Each(func(i int, s *goquery.Selection) {
+	more := true
+	find(doc.Selection, cat).EachWithBreak(func(i int, s *goquery.Selection) bool {
 		// key := fmt.Sprintf("key%v", i)
 		key := "key" + strconv.Itoa(i)
-		title := s.Find(catTitle)
+		title := find(s, catTitle)
 		res.Categories = append(res.Categories, map[string]string{
 			"key":   key,
 			"title": title.Text(),
@@ -433,81 +688,89 @@ func parseIndex(base *url.URL, ruleMap *map[string]string, doc *goquery.Document
 		itemThreadTodayCount := rule["itemThreadTodayCount"]
 		itemLastThread := rule["itemLastThread"]
 		itemLastReply := rule["itemLastReply"]
-		s.Find(items).Each(func(i int, s *goquery.Selection) {
-			elem := s.Find(itemTitle)
-			lastThread := s.Find(itemLastThread)
-			lastReply := s.Find(itemLastReply)
-			res.Items = append(res.Items, map[string]string{
+		find(s, items).EachWithBreak(func(i int, s *goquery.Selection) bool {
+			elem := find(s, itemTitle)
+			lastThread := find(s, itemLastThread)
+			lastReply := find(s, itemLastReply)
+			item := map[string]string{
 				"categoryKey":      key,
 				"title":            elem.Text(),
 				"link":             getLink(base, elem, "href"),
-				"desc":             s.Find(itemDesc).Text(),
-				"threadTodayCount": s.Find(itemThreadTodayCount).Text(),
+				"desc":             find(s, itemDesc).Text(),
+				"threadTodayCount": find(s, itemThreadTodayCount).Text(),
 				"lastThread":       lastThread.Text(),
 				"lastThreadLink":   getLink(base, lastThread, "href"),
 				"lastReply":        lastReply.Text(),
 				"lastReplyLink":    getLink(base, lastReply, "href"),
-			})
+			}
+			extractFields(base, s, r.Fields, item)
+			more = add(item)
+			return more
 		})
+		return more
 	})
 	return res
 }
 
-func parseList(base *url.URL, ruleMap *map[string]string, doc *goquery.Document) *Res {
+func parseList(base *url.URL, r *Rule, doc *goquery.Document, add itemFunc) *Res {
 	res := &Res{}
-	rule := *ruleMap
+	rule := r.Selectors
 	items := rule["items"]
 	itemTitle := rule["itemTitle"]
 	itemAuthor := rule["itemAuthor"]
 	itemAvatar := rule["itemAvatar"]
 	itemLastReply := rule["itemLastReply"]
 	itemReplyCount := rule["itemReplyCount"]
-	doc.Find(items).Each(func(i int, s *goquery.Selection) {
-		title := s.Find(itemTitle)
-		author := s.Find(itemAuthor)
-		lastReply := s.Find(itemLastReply)
-		res.Items = append(res.Items, map[string]string{
+	find(doc.Selection, items).EachWithBreak(func(i int, s *goquery.Selection) bool {
+		title := find(s, itemTitle)
+		author := find(s, itemAuthor)
+		lastReply := find(s, itemLastReply)
+		item := map[string]string{
 			"title":         title.Text(),
 			"link":          getLink(base, title, "href"),
 			"author":        author.Text(),
 			"authorLink":    getLink(base, author, "href"),
-			"avatar":        getLink(base, s.Find(itemAvatar), "src"),
+			"avatar":        getLink(base, find(s, itemAvatar), "src"),
 			"lastReply":     lastReply.Text(),
 			"lastReplyLink": getLink(base, lastReply, "href"),
-			"replyCount":    s.Find(itemReplyCount).Text(),
-		})
+			"replyCount":    find(s, itemReplyCount).Text(),
+		}
+		extractFields(base, s, r.Fields, item)
+		return add(item)
 	})
 	return res
 }
 
-func parseThread(base *url.URL, ruleMap *map[string]string, doc *goquery.Document) *Res {
+func parseThread(base *url.URL, r *Rule, doc *goquery.Document, add itemFunc) *Res {
 	res := &Res{Content: make(map[string]string)}
-	rule := *ruleMap
+	rule := r.Selectors
 	title := rule["title"]
 	body := rule["body"]
 	author := rule["author"]
 	avatar := rule["avatar"]
-	res.Content["title"] = doc.Find(title).Text()
-	html, _ := doc.Find(body).Html()
+	res.Content["title"] = find(doc.Selection, title).Text()
+	html, _ := find(doc.Selection, body).Html()
 	res.Content["body"] = html
-	res.Content["author"] = doc.Find(author).Text()
-	res.Content["avatar"] = getLink(base, doc.Find(avatar), "src")
+	res.Content["author"] = find(doc.Selection, author).Text()
+	res.Content["avatar"] = getLink(base, find(doc.Selection, avatar), "src")
 
 	items := rule["items"]
 	itemContent := rule["itemContent"]
 	itemAuthor := rule["itemAuthor"]
 	itemAvatar := rule["itemAvatar"]
 	itemNo := rule["itemNo"]
-	doc.Find(items).Each(func(i int, s *goquery.Selection) {
-		content, _ := s.Find(itemContent).Html()
-		author := s.Find(itemAuthor)
-		res.Items = append(res.Items, map[string]string{
+	find(doc.Selection, items).EachWithBreak(func(i int, s *goquery.Selection) bool {
+		content, _ := find(s, itemContent).Html()
+		author := find(s, itemAuthor)
+		item := map[string]string{
 			"content":    content,
 			"author":     author.Text(),
 			"authorLink": getLink(base, author, "href"),
-			"avatar":     getLink(base, s.Find(itemAvatar), "src"),
-			"no":         s.Find(itemNo).Text(),
-		})
+			"avatar":     getLink(base, find(s, itemAvatar), "src"),
+			"no":         find(s, itemNo).Text(),
+		}
+		extractFields(base, s, r.Fields, item)
+		return add(item)
 	})
 	return res
 }
@@ -522,40 +785,3 @@ func getLink(base *url.URL, elem *goquery.Selection, attr string) string {
 	}
 	return ""
 }
-
-func updateCookies(from []*http.Cookie, to []*http.Cookie) []*http.Cookie {
-	for _, cookie := range from {
-		ix := -1
-		for i, oc := range to {
-			if oc.Name == cookie.Name {
-				ix = i
-				break
-			}
-		}
-		if ix < 0 {
-			to = append(to, cookie)
-		} else {
-			to[ix] = cookie
-		}
-	}
-	return to
-}
-
-func convertString(s string, cts [][]string) string {
-	for _, ct := range cts {
-		if len(ct) < 1 {
-			continue
-		}
-		switch ct[0] {
-		case "hex":
-			s = hex.EncodeToString([]byte(s))
-		case "md5":
-			// hasher := md5.New()
-			// hasher.Write([]byte(s))
-			// s = string(hasher.Sum(nil))
-			hash := md5.Sum([]byte(s))
-			s = hex.EncodeToString(hash[:])
-		}
-	}
-	return s
-}
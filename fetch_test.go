@@ -1,6 +1,7 @@
 package gofetch
 
 import (
+	"context"
 	"errors"
 	"io"
 	"net/http"
@@ -9,6 +10,7 @@ import (
 	"reflect"
 	"regexp"
 	"testing"
+	"time"
 )
 
 func TestNew(t *testing.T) {
@@ -55,8 +57,19 @@ func TestIndexNoData(t *testing.T) {
 		t.Error(err)
 	}
 	res, err := f.Index("nodata")
-	if err != nil {
-		t.Error(err)
+	if !errors.Is(err, ErrConfigNotFound) {
+		t.Error("error not equals ErrConfigNotFound:", err)
+	}
+	if res != nil {
+		t.Error("have data")
+	}
+}
+
+func TestDataNoRuleMatched(t *testing.T) {
+	f, _ := New("./rule/v2ex.yaml")
+	res, err := f.Data("https://www.v2ex.com/go/python")
+	if !errors.Is(err, ErrNoRuleMatched) {
+		t.Error("error not equals ErrNoRuleMatched:", err)
 	}
 	if res != nil {
 		t.Error("have data")
@@ -188,6 +201,58 @@ func TestData(t *testing.T) {
 	}
 }
 
+func TestDataMeta(t *testing.T) {
+	config, res, err := makeData(&testConfig{
+		"./rule/v2ex.yaml",
+		"./testdata/v2ex/tech.html",
+		"https://www.v2ex.com/?tab=tech",
+	}, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	meta := res.Meta
+	if meta.URL != config.Base+"/?tab=tech" ||
+		meta.StatusCode != http.StatusOK ||
+		meta.Type != "list" ||
+		meta.Match != regexp.QuoteMeta("/?tab=tech") ||
+		len(meta.Header["Set-Cookie"]) != 2 {
+		t.Error("meta not equals:", meta)
+	}
+}
+
+func TestDataStatus(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte("blocked"))
+	}))
+	defer ts.Close()
+
+	f, _ := New("./rule/v2ex.yaml")
+	config := f.Config["v2ex"]
+	config.Base = ts.URL
+	res, err := f.Data(ts.URL + "/?tab=tech")
+	var hse *HTTPStatusError
+	if !errors.As(err, &hse) || hse.StatusCode != http.StatusForbidden || hse.Body != "blocked" {
+		t.Error("error not equals HTTPStatusError:", err)
+	}
+	if res != nil {
+		t.Error("have data", res)
+	}
+
+	config.Status = []int{200, 403}
+	res, err = f.Data(ts.URL + "/?tab=tech")
+	if err != nil || res.Meta.StatusCode != http.StatusForbidden || len(res.Items) != 0 {
+		t.Error("status not accepted:", res, err)
+	}
+
+	config.Rules[1].Status = []int{200}
+	_, err = f.Data(ts.URL + "/?tab=tech")
+	if !errors.As(err, &hse) {
+		t.Error("rule status not used:", err)
+	}
+}
+
 func TestDataHipda(t *testing.T) {
 	config, res, err := makeData(&testConfig{
 		"./rule/hipda.yaml",
@@ -310,6 +375,45 @@ func TestDataHipdaThread(t *testing.T) {
 	}
 }
 
+func TestDataContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, res, err := makeData(&testConfig{
+		"./rule/v2ex.yaml",
+		"./testdata/v2ex/tech.html",
+		"https://www.v2ex.com/?tab=tech",
+	}, func(f *Fetch, config *Config, relRef string) (*Res, error) {
+		return f.DataContext(ctx, config.Base+relRef)
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Error("error not equals context.Canceled:", err)
+	}
+	if res != nil {
+		t.Error("have data", res)
+	}
+}
+
+func TestIndexContextTimeout(t *testing.T) {
+	done := make(chan struct{})
+	defer close(done)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-done:
+		case <-r.Context().Done():
+		}
+	}))
+	defer ts.Close()
+
+	f, _ := New("./rule/v2ex.yaml")
+	f.Config["v2ex"].Base = ts.URL
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := f.IndexContext(ctx, "v2ex")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Error("error not equals context.DeadlineExceeded:", err)
+	}
+}
+
 func TestCreateLoginInfo(t *testing.T) {
 	_, _, err := makeData(&testConfig{
 		"./rule/v2ex.yaml",
@@ -479,7 +583,7 @@ func makeData(tc *testConfig, fn func(*Fetch, *Config, string) (*Res, error)) (*
 	// ref, _ := url.Parse(tc.url)
 	// relRef := ref.RequestURI()
 	relRef := tc.url[len(config.Base):]
-	(*cr.Rule)["match"] = regexp.QuoteMeta(relRef)
+	cr.Rule.Match = regexp.QuoteMeta(relRef)
 	config.Base = ts.URL
 
 	var res *Res
@@ -491,3 +595,27 @@ func makeData(tc *testConfig, fn func(*Fetch, *Config, string) (*Res, error)) (*
 	}
 	return config, res, err
 }
+
+// newTestFetch 使用 yaml 规则创建测试用的 Fetch
+func newTestFetch(t *testing.T, yaml string, opts ...Option) *Fetch {
+	t.Helper()
+	f, err := NewWithOptions(nil, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	setTestConfig(t, f, yaml)
+	return f
+}
+
+// setTestConfig 解析 yaml 规则并添加到 f 中，同 key 的规则会被替换
+func setTestConfig(t *testing.T, f *Fetch, yaml string) *Config {
+	t.Helper()
+	config, ces := parseConfig("test.yaml", []byte(yaml))
+	if len(ces) > 0 {
+		t.Fatal(ces)
+	}
+	f.mu.Lock()
+	f.setConfig(config)
+	f.mu.Unlock()
+	return config
+}
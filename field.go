@@ -0,0 +1,121 @@
+package gofetch
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Field 字段提取规则
+//
+// Source 为取值方式：text（默认）取文本，html 取内部 HTML，outerHtml 取包含自身的 HTML，
+// attr:name 取属性值，link:name 取属性值并转换为绝对地址；JSON 格式的规则参考 jsonValue
+type Field struct {
+	Name     string
+	Selector string // 为空时使用 item 或页面本身，以 xpath: 开头时为 XPath 表达式，JSON 格式的规则为 gjson 路径
+	Source   string
+	Convert  [][]string // 转换规则，与 Login.Convert 的格式相同
+	Default  string     // 转换后为空时使用的默认值
+	Type     string     // 值类型，参考 FieldType
+	Layout   string
+}
+
+// value 从 s 中提取字段的值
+func (field *Field) value(base *url.URL, s *goquery.Selection) string {
+	elem := s
+	if field.Selector != "" {
+		elem = find(s, field.Selector)
+	}
+	var v string
+	source, name := field.source()
+	switch source {
+	case "text":
+		v = elem.Text()
+	case "html":
+		v, _ = elem.Html()
+	case "outerHtml":
+		v, _ = goquery.OuterHtml(elem)
+	case "attr":
+		v = elem.AttrOr(name, "")
+	case "link":
+		v = getLink(base, elem, name)
+	}
+	return field.finish(v)
+}
+
+// finish 对提取的值执行转换规则，结果为空时使用默认值
+func (field *Field) finish(v string) string {
+	if len(field.Convert) > 0 {
+		v = convertString(v, field.Convert)
+	}
+	if v == "" {
+		v = field.Default
+	}
+	return v
+}
+
+// source 返回取值方式和属性名
+func (field *Field) source() (string, string) {
+	if field.Source == "" {
+		return "text", ""
+	}
+	i := strings.Index(field.Source, ":")
+	if i < 0 {
+		return field.Source, ""
+	}
+	return field.Source[:i], field.Source[i+1:]
+}
+
+// validate 检查字段规则，format 为规则解析的内容格式
+func (field *Field) validate(format string) error {
+	if field.Name == "" {
+		return fmt.Errorf("missing name")
+	}
+	source, name := field.source()
+	switch {
+	case format == "json" && (source == "text" || source == "raw" || source == "link") && name == "":
+	case format == "json":
+		return fmt.Errorf("%s: unknown source %q", field.Name, field.Source)
+	case source == "text", source == "html", source == "outerHtml":
+	case source == "attr", source == "link":
+		if name == "" {
+			return fmt.Errorf("%s: source %q missing attribute name", field.Name, field.Source)
+		}
+	default:
+		return fmt.Errorf("%s: unknown source %q", field.Name, field.Source)
+	}
+	if err := validateConvert(field.Convert); err != nil {
+		return fmt.Errorf("%s: %v", field.Name, err)
+	}
+	if err := (&FieldType{field.Type, field.Layout}).validate(); err != nil {
+		return fmt.Errorf("%s: %v", field.Name, err)
+	}
+	return nil
+}
+
+// extractFields 提取 fields 中的全部字段并保存到 m
+func extractFields(base *url.URL, s *goquery.Selection, fields []*Field, m map[string]string) {
+	for _, field := range fields {
+		if field == nil {
+			continue
+		}
+		m[field.Name] = field.value(base, s)
+	}
+}
+
+// parseFields 解析自定义类型的规则，items 选择器匹配的每个元素按 Fields 提取为一个 item
+func parseFields(base *url.URL, r *Rule, doc *goquery.Document, add itemFunc) *Res {
+	res := &Res{Content: make(map[string]string)}
+	items := r.Selectors["items"]
+	if items == "" || len(r.Fields) == 0 {
+		return res
+	}
+	find(doc.Selection, items).EachWithBreak(func(i int, s *goquery.Selection) bool {
+		item := make(map[string]string, len(r.Fields))
+		extractFields(base, s, r.Fields, item)
+		return add(item)
+	})
+	return res
+}
@@ -0,0 +1,83 @@
+package gofetch
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDataFields(t *testing.T) {
+	config, res, err := makeData(&testConfig{
+		"./testdata/fields/v2ex.yaml",
+		"./testdata/v2ex/tech.html",
+		"https://www.v2ex.com/?tab=tech",
+	}, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if res == nil || len(res.Items) != 50 {
+		t.Error("items not equals:", res)
+		return
+	}
+	item := res.Items[0]
+	if item["title"] != "年会被耍了 感觉很没意思 所以接下来该干啥呢" ||
+		item["node"] != "程序员" ||
+		item["nodeLink"] != config.Base+"/go/programmer" ||
+		item["views"] != "0" ||
+		item["nodeKey"] != "programmer" ||
+		item["replyCount"] != "回复 214" {
+		t.Error("item not equals:", item)
+	}
+	if res.Content["pageTitle"] != "V2EX" {
+		t.Error("content not equals:", res.Content)
+	}
+}
+
+func TestDataCustomType(t *testing.T) {
+	_, res, err := makeData(&testConfig{
+		"./testdata/fields/v2ex.yaml",
+		"./testdata/v2ex/tech.html",
+		"https://www.v2ex.com/?tab=topics",
+	}, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if res == nil || len(res.Items) != 50 {
+		t.Error("items not equals:", res)
+		return
+	}
+	one := map[string]string{
+		"title":     "年会被耍了 感觉很没意思 所以接下来该干啥呢",
+		"titleHtml": `<a href="/t/416297#reply214">年会被耍了 感觉很没意思 所以接下来该干啥呢</a>`,
+		"href":      "/t/416297#reply214",
+	}
+	if !reflect.DeepEqual(one, res.Items[0]) {
+		t.Error("item not equals:", res.Items[0])
+	}
+	if res.Meta.Type != "topics" {
+		t.Error("type not equals:", res.Meta.Type)
+	}
+}
+
+func TestFieldValidate(t *testing.T) {
+	for _, field := range []*Field{
+		{Selector: "a"},
+		{Name: "a", Source: "attr"},
+		{Name: "a", Source: "json"},
+	} {
+		if field.validate("html") == nil {
+			t.Error("field must be invalid:", field)
+		}
+	}
+	if (&Field{Name: "a", Source: "attr:href"}).validate("json") == nil {
+		t.Error("attr must be invalid for json")
+	}
+	if err := (&Field{Name: "a", Source: "raw"}).validate("json"); err != nil {
+		t.Error(err)
+	}
+	_, err := NewWithOptions([]string{"./testdata/fields/v2ex.yaml"}, WithStrict())
+	if err != nil {
+		t.Error(err)
+	}
+}
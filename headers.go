@@ -0,0 +1,70 @@
+package gofetch
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"golang.org/x/net/http/httpguts"
+)
+
+// requestHeaders 返回请求使用的请求头，按 Config.Headers、UserAgents、rule 的 Headers 的顺序覆盖，
+// 值中的 {url}、{base}、{host}、{path} 分别替换为请求地址、站点地址、请求的 host 和 path；
+// rule 为 nil 时只使用站点的请求头，rotate 为 false 时不使用 UserAgents
+func (f *Fetch) requestHeaders(config *Config, rule *Rule, req *http.Request, rotate bool) http.Header {
+	h := make(http.Header)
+	for k, vs := range config.Headers {
+		h[http.CanonicalHeaderKey(k)] = vs
+	}
+	if n := len(config.UserAgents); n > 0 && rotate {
+		i := config.uaNext.Add(1) - 1
+		h.Set("User-Agent", config.UserAgents[i%uint64(n)])
+	}
+	if rule != nil {
+		for k, vs := range rule.Headers {
+			h[http.CanonicalHeaderKey(k)] = vs
+		}
+	}
+
+	replacer := strings.NewReplacer(
+		"{url}", req.URL.String(),
+		"{base}", config.Base,
+		"{host}", req.URL.Host,
+		"{path}", req.URL.Path,
+	)
+	for k, vs := range h {
+		expanded := make([]string, len(vs))
+		for i, v := range vs {
+			expanded[i] = replacer.Replace(v)
+		}
+		h[k] = expanded
+	}
+	return h
+}
+
+// setHeaders 设置站点和规则的请求头，请求中已有的请求头（如登录时的 Content-Type）不会被覆盖
+func (f *Fetch) setHeaders(config *Config, rule *Rule, req *http.Request, rotate bool) {
+	if config == nil {
+		return
+	}
+	for k, vs := range f.requestHeaders(config, rule, req, rotate) {
+		if _, ok := req.Header[k]; !ok {
+			req.Header[k] = vs
+		}
+	}
+}
+
+// validateHeaders 检查请求头的名称和值
+func validateHeaders(headers map[string][]string) error {
+	for k, vs := range headers {
+		if !httpguts.ValidHeaderFieldName(k) {
+			return fmt.Errorf("invalid header name %q", k)
+		}
+		for _, v := range vs {
+			if !httpguts.ValidHeaderFieldValue(v) {
+				return fmt.Errorf("invalid value for header %s", k)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package gofetch
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestHeaders(t *testing.T) {
+	var mu sync.Mutex
+	var got []http.Header
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		got = append(got, r.Header.Clone())
+		mu.Unlock()
+		http.ServeFile(w, r, "./testdata/v2ex/tech.html")
+	}))
+	defer ts.Close()
+
+	f := newTestFetch(t, `key: headers
+base: `+ts.URL+`
+headers:
+  Accept-Language:
+    - zh-CN
+  referer:
+    - "{base}/index"
+userAgents:
+  - agent-a
+  - agent-b
+rules:
+  - type: list
+    match: /go/.*
+    headers:
+      Referer:
+        - "{url}#{path}"
+    items: div#Main > div:nth-child(2) > .item
+    itemTitle: .item_title > a
+  - type: list
+    match: /.*
+    items: div#Main > div:nth-child(2) > .item
+    itemTitle: .item_title > a
+`)
+	config := f.config("headers")
+
+	for _, ref := range []string{"/?tab=tech", "/go/python", "/recent"} {
+		if _, err := f.Data(ts.URL + ref); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(got) != 3 {
+		t.Fatal("requests not equals:", len(got))
+	}
+	want := []struct{ ua, referer string }{
+		{"agent-a", ts.URL + "/index"},
+		{"agent-b", ts.URL + "/go/python#/go/python"},
+		{"agent-a", ts.URL + "/index"},
+	}
+	for i, w := range want {
+		if ua := got[i].Get("User-Agent"); ua != w.ua {
+			t.Error("User-Agent not equals:", i, ua)
+		}
+		if referer := got[i].Get("Referer"); referer != w.referer {
+			t.Error("Referer not equals:", i, referer)
+		}
+		if lang := got[i].Get("Accept-Language"); lang != "zh-CN" {
+			t.Error("Accept-Language not equals:", i, lang)
+		}
+	}
+
+	// 请求中已有的请求头不会被覆盖
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+"/", nil)
+	req.Header.Set("Referer", "https://example.com/")
+	f.setHeaders(config, nil, req, true)
+	if req.Header.Get("Referer") != "https://example.com/" || req.Header.Get("User-Agent") != "agent-b" {
+		t.Error("headers not equals:", req.Header)
+	}
+
+	// 使用传入的规则，而不是按地址重新匹配
+	req, _ = http.NewRequest(http.MethodGet, ts.URL+"/recent", nil)
+	f.setHeaders(config, config.Rules[0], req, false)
+	if req.Header.Get("Referer") != ts.URL+"/recent#/recent" || req.Header.Get("User-Agent") != "" {
+		t.Error("headers not equals:", req.Header)
+	}
+}
+
+func TestHeadersValidate(t *testing.T) {
+	_, errs := parseConfig("headers.yaml", []byte(`key: a
+base: https://a.com
+headers:
+  "Bad Name":
+    - a
+rules:
+  - type: list
+    match: /.*
+    headers:
+      X-Test:
+        - "a\nb"
+`))
+	if len(errs) != 2 || errs[0].Line != 4 || errs[1].Line != 10 {
+		t.Error("errors not equals:", errs)
+	}
+}
+
+func TestLoginHeaders(t *testing.T) {
+	var got http.Header
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			got = r.Header.Clone()
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer ts.Close()
+
+	f := newTestFetch(t, `key: headers
+base: `+ts.URL+`
+login:
+  url: /signin
+  checkLogin: ok
+rules:
+  - type: form
+    match: /signin
+    headers:
+      X-Form:
+        - "{path}"
+    username: input.username
+`)
+
+	if _, err := f.Login("headers", &LoginInfo{Username: "a", Password: "b"}); err != nil {
+		t.Fatal(err)
+	}
+	// 登录地址匹配的规则中的请求头同样用于登录请求
+	if got.Get("X-Form") != "/signin" {
+		t.Error("login headers not equals:", got)
+	}
+}
@@ -0,0 +1,144 @@
+package gofetch
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+// jsonItemKeys 内置类型在 JSON 格式下每个 item 输出的字段，与解析 HTML 时相同
+var jsonItemKeys = map[string][]string{
+	"index":  {"categoryKey", "title", "link", "desc", "threadTodayCount", "lastThread", "lastThreadLink", "lastReply", "lastReplyLink"},
+	"list":   {"title", "link", "author", "authorLink", "avatar", "lastReply", "lastReplyLink", "replyCount"},
+	"thread": {"content", "author", "authorLink", "avatar", "no"},
+}
+
+// jsonContentKeys 内置类型在 JSON 格式下 Content 输出的字段
+var jsonContentKeys = map[string][]string{
+	"thread": {"title", "body", "author", "avatar"},
+}
+
+// format 返回规则解析的内容格式，type 为 json 时使用 json
+func (r *Rule) format() string {
+	if r.Format == "" && r.Type == "json" {
+		return "json"
+	}
+	if r.Format == "" {
+		return "html"
+	}
+	return r.Format
+}
+
+// parseJSON 按 gjson 路径解析 JSON 内容
+//
+// 选择器 items 为 item 数组的路径，itemXxx 为 item 中字段 xxx 的路径，
+// categories 为分类数组的路径，此时 items 相对于每个分类，其他选择器为 Content 中同名字段的路径；
+// 名称为 link、avatar 或以 Link 结尾的字段会转换为绝对地址
+func parseJSON(base *url.URL, r *Rule, doc gjson.Result, add itemFunc) *Res {
+	res := &Res{Content: make(map[string]string)}
+	for _, k := range jsonContentKeys[r.Type] {
+		res.Content[k] = ""
+	}
+	itemSelectors := make(map[string]string)
+	for k, sel := range r.Selectors {
+		switch {
+		case k == "items" || k == "categories" || strings.HasPrefix(k, "category"):
+		case strings.HasPrefix(k, "item") && len(k) > len("item"):
+			itemSelectors[strings.ToLower(k[4:5])+k[5:]] = sel
+		default:
+			res.Content[k] = jsonString(base, k, doc.Get(sel))
+		}
+	}
+
+	more := true
+	parseItems := func(parent gjson.Result, extra map[string]string) {
+		parent.Get(r.Selectors["items"]).ForEach(func(_, v gjson.Result) bool {
+			item := make(map[string]string, len(jsonItemKeys[r.Type])+len(itemSelectors)+len(r.Fields))
+			for _, k := range jsonItemKeys[r.Type] {
+				item[k] = ""
+			}
+			for k, v := range extra {
+				item[k] = v
+			}
+			for k, sel := range itemSelectors {
+				item[k] = jsonString(base, k, v.Get(sel))
+			}
+			extractJSONFields(base, v, r.Fields, item)
+			more = add(item)
+			return more
+		})
+	}
+	if cat := r.Selectors["categories"]; cat != "" {
+		i := 0
+		doc.Get(cat).ForEach(func(_, v gjson.Result) bool {
+			key := "key" + strconv.Itoa(i)
+			i++
+			res.Categories = append(res.Categories, map[string]string{
+				"key":   key,
+				"title": v.Get(r.Selectors["categoryTitle"]).String(),
+				"link":  jsonString(base, "link", v.Get(r.Selectors["categoryLink"])),
+			})
+			parseItems(v, map[string]string{"categoryKey": key})
+			return more
+		})
+	} else if r.Selectors["items"] != "" {
+		parseItems(doc, nil)
+	}
+	extractJSONFields(base, doc, r.Content, res.Content)
+	if r.Pagination != nil && r.Pagination.Next != "" {
+		res.Meta.Next = resolveLink(base, doc.Get(r.Pagination.Next).String())
+	}
+	return res
+}
+
+// jsonString 获取 JSON 值的字符串，链接类字段转换为绝对地址
+func jsonString(base *url.URL, name string, v gjson.Result) string {
+	if name == "link" || name == "avatar" || strings.HasSuffix(name, "Link") {
+		return resolveLink(base, v.String())
+	}
+	return v.String()
+}
+
+// resolveLink 将 ref 转换为相对于 base 的绝对地址，ref 为空或无法解析时返回空字符串
+func resolveLink(base *url.URL, ref string) string {
+	if ref == "" {
+		return ""
+	}
+	link, err := url.Parse(ref)
+	if err != nil {
+		return ""
+	}
+	return base.ResolveReference(link).String()
+}
+
+// jsonValue 从 JSON 值 v 中提取字段的值，Selector 为 gjson 路径
+//
+// Source 为 text（默认）时取字符串值，raw 取原始 JSON，link 取字符串值并转换为绝对地址
+func (field *Field) jsonValue(base *url.URL, v gjson.Result) string {
+	elem := v
+	if field.Selector != "" {
+		elem = v.Get(field.Selector)
+	}
+	var s string
+	switch source, _ := field.source(); source {
+	case "raw":
+		s = elem.Raw
+	case "link":
+		s = resolveLink(base, elem.String())
+	default:
+		s = elem.String()
+	}
+	return field.finish(s)
+}
+
+// extractJSONFields 从 JSON 值中提取 fields 中的全部字段并保存到 m
+func extractJSONFields(base *url.URL, v gjson.Result, fields []*Field, m map[string]string) {
+	for _, field := range fields {
+		if field == nil {
+			continue
+		}
+		m[field.Name] = field.jsonValue(base, v)
+	}
+}
@@ -0,0 +1,92 @@
+package gofetch
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDataJSON(t *testing.T) {
+	config, res, err := makeData(&testConfig{
+		"./testdata/json/v2ex.yaml",
+		"./testdata/json/hot.json",
+		"https://www.v2ex.com/api/topics/hot.json",
+	}, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if res == nil || len(res.Items) != 2 {
+		t.Error("items not equals:", res)
+		return
+	}
+	item := res.Items[0]
+	if item["title"] != "年会被耍了 感觉很没意思 所以接下来该干啥呢" ||
+		item["link"] != config.Base+"/t/416297" ||
+		item["author"] != "MrFireAwayH" ||
+		item["authorLink"] != config.Base+"/member/MrFireAwayH" ||
+		item["avatar"] != "http://cdn.v2ex.com/avatar/1.png" ||
+		item["replyCount"] != "214" ||
+		item["node"] != "程序员" ||
+		item["member"][0] != '{' {
+		t.Error("item not equals:", item)
+	}
+	if v, ok := item["lastReply"]; !ok || v != "" {
+		t.Error("lastReply must be empty:", item)
+	}
+	if n, err := res.ItemInt(1, "created"); n != 1514262000 || err != nil {
+		t.Error("created not equals:", n, err)
+	}
+	if res.Meta.Type != "list" {
+		t.Error("type not equals:", res.Meta.Type)
+	}
+}
+
+func TestDataJSONContent(t *testing.T) {
+	config, res, err := makeData(&testConfig{
+		"./testdata/json/v2ex.yaml",
+		"./testdata/json/node.json",
+		"https://www.v2ex.com/api/nodes/show.json?name=programmer",
+	}, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if res.Content["title"] != "程序员" ||
+		res.Content["topics"] != "28340" ||
+		res.Content["link"] != config.Base+"/go/programmer" ||
+		res.Content["header"] != "While code monkeys are not eating bananas, they're coding." ||
+		len(res.Items) != 0 {
+		t.Error("content not equals:", res.Content, res.Items)
+	}
+}
+
+func TestDataInvalidJSON(t *testing.T) {
+	_, _, err := makeData(&testConfig{
+		"./testdata/json/v2ex.yaml",
+		"./testdata/v2ex/tech.html",
+		"https://www.v2ex.com/api/topics/hot.json",
+	}, nil)
+	if !errors.Is(err, ErrInvalidJSON) {
+		t.Error("error not equals:", err)
+	}
+}
+
+func TestJSONValidate(t *testing.T) {
+	_, err := NewWithOptions([]string{"./testdata/json/v2ex.yaml"}, WithStrict())
+	if err != nil {
+		t.Error(err)
+	}
+	_, errs := parseConfig("json.yaml", []byte(`key: a
+base: https://a.com
+rules:
+  - type: list
+    format: xml
+    match: /
+  - type: form
+    format: json
+    match: /login
+`))
+	if len(errs) != 2 {
+		t.Error("errors not equals:", errs)
+	}
+}
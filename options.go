@@ -0,0 +1,198 @@
+package gofetch
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Option 创建 Fetch 时的可选配置
+type Option func(*Fetch) error
+
+// ClientConfig 站点独立的 http.Client 配置
+type ClientConfig struct {
+	Timeout            time.Duration
+	Proxy              string
+	InsecureSkipVerify bool `yaml:"insecureSkipVerify"`
+}
+
+// WithStrict 严格加载规则文件，任何文件读取、解析或校验失败时 New 返回 ConfigErrors
+func WithStrict() Option {
+	return func(f *Fetch) error {
+		f.strict = true
+		return nil
+	}
+}
+
+// WithClient 使用指定的 http.Client 发送请求，client.Jar 会被站点的 CookieJar 替换；
+// WithTimeout、WithTransport、WithProxy 总是在其之后应用，与参数的顺序无关
+func WithClient(client *http.Client) Option {
+	return func(f *Fetch) error {
+		if client == nil {
+			return errors.New("client is nil")
+		}
+		c := *client
+		f.client = &c
+		return nil
+	}
+}
+
+// WithTimeout 设置请求超时时间
+func WithTimeout(timeout time.Duration) Option {
+	return func(f *Fetch) error {
+		f.clientOpts = append(f.clientOpts, func(c *http.Client) {
+			c.Timeout = timeout
+		})
+		return nil
+	}
+}
+
+// WithTransport 设置请求使用的 http.RoundTripper
+func WithTransport(transport http.RoundTripper) Option {
+	return func(f *Fetch) error {
+		f.clientOpts = append(f.clientOpts, func(c *http.Client) {
+			c.Transport = transport
+		})
+		return nil
+	}
+}
+
+// WithProxy 设置请求使用的代理地址，在 WithTransport 之后应用，transport 需要是 *http.Transport
+func WithProxy(proxy string) Option {
+	return func(f *Fetch) error {
+		if _, err := parseProxy(proxy); err != nil {
+			return err
+		}
+		f.proxy = proxy
+		return nil
+	}
+}
+
+// applyClientOptions 在 WithClient 的基础上应用 WithTimeout、WithTransport、WithProxy
+func (f *Fetch) applyClientOptions() error {
+	for _, opt := range f.clientOpts {
+		opt(f.client)
+	}
+	if f.proxy == "" {
+		return nil
+	}
+	t, err := cloneTransport(f.client.Transport)
+	if err != nil {
+		return err
+	}
+	if err := setProxy(t, f.proxy); err != nil {
+		return err
+	}
+	f.client.Transport = t
+	return nil
+}
+
+// newConfigClient 在 base 的基础上根据站点配置创建 http.Client
+func newConfigClient(base *http.Client, cc *ClientConfig) (*http.Client, error) {
+	c := *base
+	if cc.Timeout > 0 {
+		c.Timeout = cc.Timeout
+	}
+	if cc.Proxy != "" || cc.InsecureSkipVerify {
+		t, err := cloneTransport(base.Transport)
+		if err != nil {
+			return nil, err
+		}
+		if cc.Proxy != "" {
+			err = setProxy(t, cc.Proxy)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if cc.InsecureSkipVerify {
+			if t.TLSClientConfig == nil {
+				t.TLSClientConfig = &tls.Config{}
+			}
+			t.TLSClientConfig.InsecureSkipVerify = true
+		}
+		c.Transport = t
+	}
+	return &c, nil
+}
+
+func cloneTransport(rt http.RoundTripper) (*http.Transport, error) {
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	t, ok := rt.(*http.Transport)
+	if !ok {
+		return nil, errors.New("transport is not *http.Transport")
+	}
+	return t.Clone(), nil
+}
+
+func setProxy(t *http.Transport, proxy string) error {
+	u, err := parseProxy(proxy)
+	if err != nil {
+		return err
+	}
+	t.Proxy = http.ProxyURL(u)
+	return nil
+}
+
+// parseProxy 解析代理地址，需要是包含 host 的绝对地址
+func parseProxy(proxy string) (*url.URL, error) {
+	u, err := url.Parse(proxy)
+	if err != nil {
+		return nil, err
+	}
+	if !u.IsAbs() || u.Host == "" {
+		return nil, fmt.Errorf("proxy %q is not an absolute URL", proxy)
+	}
+	return u, nil
+}
+
+// httpClient 获取站点对应的 http.Client
+func (f *Fetch) httpClient(config *Config) *http.Client {
+	if config != nil {
+		f.mu.RLock()
+		c, ok := f.clients[config.Key]
+		f.mu.RUnlock()
+		if ok {
+			return c
+		}
+	}
+	return f.client
+}
+
+// do 使用站点对应的 http.Client 和请求头发送请求，rule 为请求匹配的规则，可以为 nil，
+// 请求前检查 robots.txt，失败时按站点的 Retry 重试，离线模式下返回 ErrNotCached
+func (f *Fetch) do(config *Config, rule *Rule, req *http.Request) (*http.Response, error) {
+	if f.offline {
+		return nil, ErrNotCached
+	}
+	f.setHeaders(config, rule, req, true)
+	if err := f.checkRobots(config, req); err != nil {
+		return nil, err
+	}
+	return f.doRetry(config, req)
+}
+
+// doOnce 发送一次请求，请求前按站点的 RateLimit 等待
+func (f *Fetch) doOnce(config *Config, req *http.Request) (*http.Response, error) {
+	release := func() {}
+	if l := f.limiter(config); l != nil {
+		var err error
+		if release, err = l.wait(req.Context()); err != nil {
+			return nil, err
+		}
+	}
+	resp, err := f.httpClient(config).Do(req)
+	if config != nil {
+		f.saveSession(config.Key, false)
+	}
+	if err != nil {
+		release()
+		return nil, err
+	}
+	resp.Body = &releaseBody{resp.Body, release}
+	return resp, nil
+}
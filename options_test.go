@@ -0,0 +1,143 @@
+package gofetch
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (fn roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return fn(req)
+}
+
+func TestWithTransport(t *testing.T) {
+	called := 0
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		called++
+		file, err := os.Open("./testdata/v2ex/tech.html")
+		if err != nil {
+			return nil, err
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {"text/html; charset=utf-8"}},
+			Body:       file,
+			Request:    req,
+		}, nil
+	})
+	f, err := NewWithOptions([]string{"./rule/v2ex.yaml"}, WithTransport(transport), WithTimeout(time.Second))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	res, err := f.Data("https://www.v2ex.com/?tab=tech")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if called != 1 {
+		t.Error("transport not used:", called)
+	}
+	if res == nil || len(res.Items) != 50 {
+		t.Error("items not equals:", res)
+	}
+	if f.client.Timeout != time.Second {
+		t.Error("timeout not equals:", f.client.Timeout)
+	}
+}
+
+func TestWithProxy(t *testing.T) {
+	f, err := NewWithOptions(nil, WithProxy("http://127.0.0.1:1080"))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	transport, ok := f.client.Transport.(*http.Transport)
+	if !ok || transport.Proxy == nil {
+		t.Error("proxy not set")
+		return
+	}
+	req, _ := http.NewRequest("GET", "https://www.v2ex.com", nil)
+	u, err := transport.Proxy(req)
+	if err != nil || u.String() != "http://127.0.0.1:1080" {
+		t.Error("proxy not equals:", u, err)
+	}
+
+	_, err = NewWithOptions(nil, WithTransport(roundTripFunc(nil)), WithProxy("http://127.0.0.1:1080"))
+	if err == nil {
+		t.Error("custom transport must not accept proxy")
+	}
+	if _, err := NewWithOptions(nil, WithProxy("127.0.0.1:1080")); err == nil {
+		t.Error("proxy without scheme should fail")
+	}
+}
+
+func TestWithClientOrder(t *testing.T) {
+	transport := roundTripFunc(nil)
+	// WithClient 放在后面时也不会覆盖之前的 WithTimeout、WithTransport
+	f, err := NewWithOptions(nil, WithTimeout(time.Second), WithTransport(transport), WithClient(&http.Client{Timeout: time.Minute}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.client.Timeout != time.Second || f.client.Transport == nil {
+		t.Error("client options lost:", f.client.Timeout, f.client.Transport)
+	}
+
+	f, err = NewWithOptions(nil, WithProxy("http://127.0.0.1:1080"), WithTransport(&http.Transport{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tr, ok := f.client.Transport.(*http.Transport); !ok || tr.Proxy == nil {
+		t.Error("proxy not set")
+	}
+}
+
+func TestConfigClientProxy(t *testing.T) {
+	_, errs := parseConfig("proxy.yaml", []byte(`key: a
+base: https://a.com
+client:
+  timeout: 3s
+  proxy: 127.0.0.1:1080
+`))
+	if len(errs) != 1 || errs[0].Line != 5 {
+		t.Error("errors not equals:", errs)
+	}
+}
+
+func TestConfigClient(t *testing.T) {
+	content, err := os.ReadFile("./rule/v2ex.yaml")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	s := strings.Replace(string(content), "index:", "client:\n  timeout: 3s\n  insecureSkipVerify: true\nindex:", 1)
+	path := filepath.Join(t.TempDir(), "v2ex.yaml")
+	err = os.WriteFile(path, []byte(s), 0644)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	f, err := NewWithOptions([]string{path}, WithTimeout(time.Second))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	c := f.httpClient(f.Config["v2ex"])
+	if c == f.client || c.Timeout != 3*time.Second {
+		t.Error("config client not used:", c.Timeout)
+		return
+	}
+	transport, ok := c.Transport.(*http.Transport)
+	if !ok || !transport.TLSClientConfig.InsecureSkipVerify {
+		t.Error("insecureSkipVerify not set")
+	}
+	if f.httpClient(nil) != f.client {
+		t.Error("default client not used")
+	}
+}
@@ -0,0 +1,205 @@
+package gofetch
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Pagination 分页规则
+//
+// Next 不为空时按下一页链接翻页，否则按 URL 模板生成页码地址，直到某一页没有 item 或与上一页相同；
+// 第一页为请求的地址，页码从 1 开始
+type Pagination struct {
+	Next     string // 下一页链接的选择器，JSON 格式的规则为 gjson 路径
+	URL      string // 页码地址模板，{page} 替换为页码，可以使用 $1 引用 match 中的分组
+	MaxPages int    `yaml:"maxPages"` // 最多获取的页数，为 0 时不限制
+}
+
+// validate 检查分页规则
+func (p *Pagination) validate() error {
+	if p.Next == "" && p.URL == "" {
+		return fmt.Errorf("missing next or url")
+	}
+	if p.URL != "" && !strings.Contains(p.URL, "{page}") {
+		return fmt.Errorf("url %q missing {page}", p.URL)
+	}
+	if p.MaxPages < 0 {
+		return fmt.Errorf("maxPages %d is negative", p.MaxPages)
+	}
+	return nil
+}
+
+// pageURL 返回第 page 页的地址，ref 为第一页的地址
+func (r *Rule) pageURL(base, ref string, page int) string {
+	u := r.expandURL(base, ref, r.Pagination.URL)
+	return strings.ReplaceAll(u, "{page}", strconv.Itoa(page))
+}
+
+// Pages 按规则的分页设置依次获取 ref 开始的每一页，规则没有分页设置时只获取 ref，
+// 每页的 Meta.Page 和每个 item 的 page 字段为页码，出错时停止
+func (f *Fetch) Pages(ctx context.Context, ref string) iter.Seq2[*Res, error] {
+	return func(yield func(*Res, error) bool) {
+		f.walkPages(ctx, ref, nil, yield)
+	}
+}
+
+// Items 与 Pages 相同，但每解析出一个 item 就交给调用方而不保存在 Res 中，
+// 循环提前结束时立即停止解析，并且不再获取后面的页
+func (f *Fetch) Items(ctx context.Context, ref string) iter.Seq2[map[string]string, error] {
+	return func(yield func(map[string]string, error) bool) {
+		f.walkPages(ctx, ref, func(item map[string]string) bool {
+			return yield(item, nil)
+		}, func(res *Res, err error) bool {
+			if err != nil {
+				yield(nil, err)
+				return false
+			}
+			return true
+		})
+	}
+}
+
+// walkPages 依次获取 ref 开始的每一页，onItem 不为 nil 时解析出的 item 逐个交给 onItem，
+// onPage 在每页完成或出错时调用，onItem 或 onPage 返回 false 时停止
+func (f *Fetch) walkPages(ctx context.Context, ref string, onItem itemFunc, onPage func(*Res, error) bool) {
+	cr := f.matchConfigRule(ref)
+	if cr == nil {
+		onPage(nil, ErrNoRuleMatched)
+		return
+	}
+	p := cr.Rule.Pagination
+	seen := make(map[string]bool)
+	next := ref
+	var prevKeys []string // 上一页每个 item 的标识
+	for page := 1; ; page++ {
+		seen[next] = true
+		// 按页码模板翻页时，超出最后一页的页码通常返回最后一页，与上一页相同时停止
+		checkRepeat := page > 1 && p != nil && p.Next == "" && len(prevKeys) > 0
+		var keys []string
+		var pending []map[string]string // 与上一页相同的 item，确定不是重复页后再交给 onItem
+		count := 0
+		stopped := false
+		emit := func(item map[string]string) bool {
+			count++
+			item["page"] = strconv.Itoa(page)
+			stopped = !onItem(item)
+			return !stopped
+		}
+		fetch := func(u, typ string) (*Res, error) {
+			var yield itemFunc
+			if onItem != nil {
+				yield = func(item map[string]string) bool {
+					keys = append(keys, itemKey(item))
+					if checkRepeat {
+						if i := len(keys) - 1; i < len(prevKeys) && keys[i] == prevKeys[i] {
+							pending = append(pending, item)
+							return true
+						}
+						checkRepeat = false
+						for _, it := range pending {
+							if !emit(it) {
+								return false
+							}
+						}
+						pending = nil
+					}
+					return emit(item)
+				}
+			}
+			res, err := f.fetchRes(ctx, cr, u, typ, yield)
+			if err == nil && onItem == nil {
+				count = len(res.Items)
+				for _, item := range res.Items {
+					keys = append(keys, itemKey(item))
+					item["page"] = strconv.Itoa(page)
+				}
+			}
+			return res, err
+		}
+
+		res, err := fetch(next, cr.Rule.Type)
+		if err == nil && page == 1 && len(keys) == 0 && cr.Rule.Feed != "" {
+			p = nil
+			res, err = fetch(cr.Rule.expandURL(cr.Config.Base, ref, cr.Rule.Feed), "feed")
+		}
+		if stopped {
+			return
+		}
+		if err != nil {
+			onPage(nil, err)
+			return
+		}
+		if checkRepeat && slices.Equal(keys, prevKeys) {
+			return
+		}
+		for _, it := range pending {
+			if !emit(it) {
+				return
+			}
+		}
+		prevKeys = keys
+		res.Meta.Page = page
+		if !onPage(res, nil) {
+			return
+		}
+
+		if p == nil || p.MaxPages > 0 && page >= p.MaxPages {
+			return
+		}
+		switch {
+		case p.Next != "":
+			next = res.Meta.Next
+		case count == 0:
+			return
+		default:
+			next = cr.Rule.pageURL(cr.Config.Base, ref, page+1)
+		}
+		if next == "" || seen[next] {
+			return
+		}
+	}
+}
+
+// itemKey 返回 item 的标识，有链接时使用链接，否则使用全部字段
+func itemKey(item map[string]string) string {
+	if link := item["link"]; link != "" {
+		return link
+	}
+	var b strings.Builder
+	for _, k := range sortedKeys(item) {
+		if k != "page" {
+			b.WriteString(k + "=" + item[k] + "\n")
+		}
+	}
+	return b.String()
+}
+
+// DataAll 获取 ref 开始的全部分页并合并为一个结果
+func (f *Fetch) DataAll(ref string) (*Res, error) {
+	return f.DataAllContext(context.Background(), ref)
+}
+
+// DataAllContext 获取 ref 开始的全部分页并合并为一个结果，ctx 取消后停止
+//
+// Content、Categories 和 Meta 使用第一页的结果，Items 按页顺序合并，
+// Meta.Page 为获取的页数，Meta.Next 为最后一页的下一页地址
+func (f *Fetch) DataAllContext(ctx context.Context, ref string) (*Res, error) {
+	var all *Res
+	for res, err := range f.Pages(ctx, ref) {
+		if err != nil {
+			return nil, err
+		}
+		if all == nil {
+			all = res
+			continue
+		}
+		all.Items = append(all.Items, res.Items...)
+		all.Meta.Page = res.Meta.Page
+		all.Meta.Next = res.Meta.Next
+	}
+	return all, nil
+}
@@ -0,0 +1,255 @@
+package gofetch
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// servePage 返回第 page 页的 v2ex 列表，每页帖子的链接不同
+func servePage(w http.ResponseWriter, page string) {
+	if page == "" {
+		page = "1"
+	}
+	content, _ := os.ReadFile("./testdata/v2ex/tech.html")
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(strings.ReplaceAll(string(content), `href="/t/`, `href="/t/`+page)))
+}
+
+func TestDataAll(t *testing.T) {
+	var pages []string
+	config, res, err := makeData(&testConfig{
+		"./rule/hipda.yaml",
+		"./testdata/hipda/forumdisplay.html",
+		"https://www.hi-pda.com/forum/forumdisplay.php?fid=5",
+	}, func(f *Fetch, config *Config, relRef string) (*Res, error) {
+		for res, err := range f.Pages(context.Background(), config.Base+relRef) {
+			if err != nil {
+				return nil, err
+			}
+			pages = append(pages, res.Meta.URL)
+		}
+		return f.DataAll(config.Base + relRef)
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	// 第二页的下一页链接仍指向第二页，不会重复获取
+	if len(pages) != 2 || pages[1] != config.Base+"/forumdisplay.php?fid=5&page=2" {
+		t.Error("pages not equals:", pages)
+	}
+	if res == nil || len(res.Items) != 2*65 || res.Meta.Page != 2 {
+		t.Error("items not equals:", len(res.Items), res.Meta)
+		return
+	}
+	if res.Items[0]["page"] != "1" || res.Items[65]["page"] != "2" || res.Items[0]["title"] != res.Items[65]["title"] {
+		t.Error("page not equals:", res.Items[0], res.Items[65])
+	}
+	if res.Meta.Next != config.Base+"/forumdisplay.php?fid=5&page=2" {
+		t.Error("next not equals:", res.Meta.Next)
+	}
+}
+
+func TestPagesTemplate(t *testing.T) {
+	var paths []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.RequestURI())
+		if r.URL.Query().Get("p") == "4" {
+			w.Write([]byte("<html></html>"))
+			return
+		}
+		servePage(w, r.URL.Query().Get("p"))
+	}))
+	defer ts.Close()
+
+	f := newTestFetch(t, `key: v2ex
+base: `+ts.URL+`
+rules:
+  - type: list
+    match: /go/(\w+)
+    items: div#Main > div:nth-child(2) > .item
+    itemTitle: .item_title > a
+    pagination:
+      url: /go/$1?p={page}
+`)
+
+	var counts []int
+	for res, err := range f.Pages(context.Background(), ts.URL+"/go/programmer") {
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		counts = append(counts, len(res.Items))
+		if len(res.Items) > 0 && res.Items[0]["page"] != strconv.Itoa(res.Meta.Page) {
+			t.Error("page not equals:", res.Meta.Page, res.Items[0]["page"])
+		}
+	}
+	if len(counts) != 4 || counts[3] != 0 || paths[1] != "/go/programmer?p=2" {
+		t.Error("pages not equals:", counts, paths)
+	}
+
+	// 提前结束时不再请求下一页
+	paths = nil
+	for range f.Pages(context.Background(), ts.URL+"/go/programmer") {
+		break
+	}
+	if len(paths) != 1 {
+		t.Error("paths not equals:", paths)
+	}
+
+	f.config("v2ex").Rules[0].Pagination.MaxPages = 2
+	res, err := f.DataAll(ts.URL + "/go/programmer")
+	if err != nil || res.Meta.Page != 2 || len(res.Items) != 100 {
+		t.Error("res not equals:", err, res.Meta)
+	}
+}
+
+func TestPaginationValidate(t *testing.T) {
+	_, errs := parseConfig("page.yaml", []byte(`key: a
+base: https://a.com
+rules:
+  - type: list
+    match: /a
+    pagination:
+      maxPages: 2
+  - type: list
+    match: /b
+    pagination:
+      url: /b?page=2
+  - type: list
+    match: /c
+    pagination:
+      next: a.next >
+`))
+	if len(errs) != 3 {
+		t.Error("errors not equals:", errs)
+	}
+}
+
+func TestItems(t *testing.T) {
+	var items []map[string]string
+	_, _, err := makeData(&testConfig{
+		"./rule/hipda.yaml",
+		"./testdata/hipda/forumdisplay.html",
+		"https://www.hi-pda.com/forum/forumdisplay.php?fid=5",
+	}, func(f *Fetch, config *Config, relRef string) (*Res, error) {
+		for item, err := range f.Items(context.Background(), config.Base+relRef) {
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+			if len(items) == 3 {
+				break
+			}
+		}
+		if len(items) != 3 || items[2]["page"] != "1" || items[2]["title"] == "" {
+			t.Error("items not equals:", items)
+		}
+
+		items = nil
+		for item, err := range f.Items(context.Background(), config.Base+relRef) {
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		if len(items) != 2*65 || items[65]["page"] != "2" {
+			t.Error("items not equals:", len(items))
+		}
+
+		for _, err := range f.Items(context.Background(), "https://example.com/") {
+			if err != ErrNoRuleMatched {
+				t.Error("error not equals:", err)
+			}
+		}
+		return nil, nil
+	})
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestItemsStopFetching(t *testing.T) {
+	var paths []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.RequestURI())
+		servePage(w, r.URL.Query().Get("p"))
+	}))
+	defer ts.Close()
+
+	f := newTestFetch(t, `key: v2ex
+base: `+ts.URL+`
+rules:
+  - type: list
+    match: /go/(\w+)
+    items: div#Main > div:nth-child(2) > .item
+    itemTitle: .item_title > a
+    pagination:
+      url: /go/$1?p={page}
+`)
+
+	n := 0
+	for _, err := range f.Items(context.Background(), ts.URL+"/go/programmer") {
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		n++
+		if n == 60 {
+			break
+		}
+	}
+	if len(paths) != 2 {
+		t.Error("paths not equals:", paths)
+	}
+}
+
+func TestPagesRepeated(t *testing.T) {
+	var paths []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.RequestURI())
+		// 超过最后一页的页码返回最后一页
+		page, _ := strconv.Atoi(r.URL.Query().Get("p"))
+		servePage(w, strconv.Itoa(min(max(page, 1), 3)))
+	}))
+	defer ts.Close()
+
+	f := newTestFetch(t, `key: v2ex
+base: `+ts.URL+`
+rules:
+  - type: list
+    match: /go/(\w+)
+    items: div#Main > div:nth-child(2) > .item
+    itemTitle: .item_title > a
+    pagination:
+      url: /go/$1?p={page}
+`)
+
+	res, err := f.DataAll(ts.URL + "/go/programmer")
+	if err != nil || res.Meta.Page != 3 || len(res.Items) != 150 {
+		t.Error("res not equals:", err, res.Meta)
+	}
+	if len(paths) != 4 {
+		t.Error("paths not equals:", paths)
+	}
+
+	paths = nil
+	n := 0
+	for item, err := range f.Items(context.Background(), ts.URL+"/go/programmer") {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if item["page"] == "4" {
+			t.Error("repeated page returned:", item)
+		}
+		n++
+	}
+	if n != 150 || len(paths) != 4 {
+		t.Error("items not equals:", n, paths)
+	}
+}
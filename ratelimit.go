@@ -0,0 +1,152 @@
+package gofetch
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// RateLimit 站点的请求频率限制，同一个 Fetch 的所有 goroutine 共用
+type RateLimit struct {
+	RPS      float64       `yaml:"rps"`      // 每秒请求数，为 0 时不限制
+	Burst    int           `yaml:"burst"`    // 允许连续发出的请求数，默认为 1
+	MinDelay time.Duration `yaml:"minDelay"` // 两次请求开始之间的最小间隔
+	Jitter   time.Duration `yaml:"jitter"`   // 每次请求额外等待 0 到 Jitter 之间的随机时间
+	MaxConns int           `yaml:"maxConns"` // 最大并发请求数，请求在响应 Body 关闭后结束，为 0 时不限制
+}
+
+// validate 检查频率限制的配置
+func (rl *RateLimit) validate() error {
+	switch {
+	case rl.RPS < 0:
+		return fmt.Errorf("rps %v is negative", rl.RPS)
+	case rl.Burst < 0:
+		return fmt.Errorf("burst %d is negative", rl.Burst)
+	case rl.MinDelay < 0:
+		return fmt.Errorf("minDelay %v is negative", rl.MinDelay)
+	case rl.Jitter < 0:
+		return fmt.Errorf("jitter %v is negative", rl.Jitter)
+	case rl.MaxConns < 0:
+		return fmt.Errorf("maxConns %d is negative", rl.MaxConns)
+	}
+	return nil
+}
+
+// limiter 按 RateLimit 限制请求，RPS 和 Burst 使用令牌桶（GCRA）实现
+type limiter struct {
+	rl   RateLimit
+	mu   sync.Mutex
+	tat  time.Time // 令牌桶的理论到达时间
+	last time.Time // 上一次请求开始的时间
+	// crawlDelay robots.txt 中的 Crawl-delay，与 MinDelay 取较大值
+	crawlDelay time.Duration
+	conns      chan struct{}
+	now        func() time.Time
+}
+
+func newLimiter(rl RateLimit) *limiter {
+	l := &limiter{rl: rl, now: time.Now}
+	if rl.MaxConns > 0 {
+		l.conns = make(chan struct{}, rl.MaxConns)
+	}
+	return l
+}
+
+// reserve 预留一次请求，返回可以开始请求的时间
+func (l *limiter) reserve() time.Time {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	at := now
+	var interval time.Duration
+	if l.rl.RPS > 0 {
+		interval = time.Duration(float64(time.Second) / l.rl.RPS)
+		burst := max(l.rl.Burst, 1)
+		if l.tat.Before(now) {
+			l.tat = now
+		}
+		if t := l.tat.Add(-time.Duration(burst-1) * interval); t.After(at) {
+			at = t
+		}
+	}
+	if delay := max(l.rl.MinDelay, l.crawlDelay); delay > 0 && !l.last.IsZero() {
+		if t := l.last.Add(delay); t.After(at) {
+			at = t
+		}
+	}
+	if l.rl.Jitter > 0 {
+		at = at.Add(rand.N(l.rl.Jitter))
+	}
+	if interval > 0 {
+		if at.After(l.tat) {
+			l.tat = at
+		}
+		l.tat = l.tat.Add(interval)
+	}
+	l.last = at
+	return at
+}
+
+// wait 等待到可以发出请求，返回的 release 在请求结束时调用
+func (l *limiter) wait(ctx context.Context) (release func(), err error) {
+	release = func() {}
+	if l.conns != nil {
+		select {
+		case l.conns <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		var once sync.Once
+		release = func() {
+			once.Do(func() { <-l.conns })
+		}
+	}
+	if d := l.reserve().Sub(l.now()); d > 0 {
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			release()
+			return nil, ctx.Err()
+		}
+	}
+	return release, nil
+}
+
+// releaseBody 关闭响应 Body 时释放并发请求数
+type releaseBody struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b *releaseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.release()
+	return err
+}
+
+// limiter 获取站点的频率限制，没有配置时返回 nil
+func (f *Fetch) limiter(config *Config) *limiter {
+	if config == nil {
+		return nil
+	}
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.limiters[config.Key]
+}
+
+// setLimiter 按规则设置站点的频率限制，配置没有变化时保留原有状态，需要持有 f.mu 的写锁
+func (f *Fetch) setLimiter(config *Config) {
+	if config.RateLimit == nil {
+		delete(f.limiters, config.Key)
+		return
+	}
+	if l, ok := f.limiters[config.Key]; ok && l.rl == *config.RateLimit {
+		return
+	}
+	f.limiters[config.Key] = newLimiter(*config.RateLimit)
+}
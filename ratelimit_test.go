@@ -0,0 +1,107 @@
+package gofetch
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLimiterReserve(t *testing.T) {
+	now := time.Date(2018, 3, 31, 10, 2, 0, 0, time.UTC)
+	l := newLimiter(RateLimit{RPS: 2, Burst: 2})
+	l.now = func() time.Time { return now }
+	var got []time.Duration
+	for i := 0; i < 4; i++ {
+		got = append(got, l.reserve().Sub(now))
+	}
+	want := []time.Duration{0, 0, 500 * time.Millisecond, time.Second}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Error("reserve not equals:", got)
+			break
+		}
+	}
+
+	l = newLimiter(RateLimit{MinDelay: time.Second, Jitter: 100 * time.Millisecond})
+	l.now = func() time.Time { return now }
+	first, second := l.reserve().Sub(now), l.reserve().Sub(now)
+	if first < 0 || first >= 100*time.Millisecond || second < time.Second+first || second >= time.Second+first+100*time.Millisecond {
+		t.Error("reserve not equals:", first, second)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	var active, peak int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&active, 1)
+		defer atomic.AddInt32(&active, -1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		http.ServeFile(w, r, "./testdata/v2ex/tech.html")
+	}))
+	defer ts.Close()
+
+	f := newTestFetch(t, `key: v2ex
+base: `+ts.URL+`
+rateLimit:
+  rps: 20
+  burst: 2
+  minDelay: 10ms
+  maxConns: 2
+rules:
+  - type: list
+    match: /.*
+    items: div#Main > div:nth-child(2) > .item
+    itemTitle: .item_title > a
+`)
+	if d := f.config("v2ex").RateLimit.MinDelay; d != 10*time.Millisecond {
+		t.Error("minDelay not equals:", d)
+	}
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := f.Data(ts.URL + "/"); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	// 20 rps 且 burst 为 2 时 6 个请求至少需要 200ms
+	if d := time.Since(start); d < 200*time.Millisecond {
+		t.Error("requests too fast:", d)
+	}
+	if peak > 2 {
+		t.Error("concurrent requests not limited:", peak)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := f.DataContext(ctx, ts.URL+"/"); !errors.Is(err, context.Canceled) {
+		t.Error("error not equals:", err)
+	}
+}
+
+func TestRateLimitValidate(t *testing.T) {
+	_, errs := parseConfig("rate.yaml", []byte(`key: a
+base: https://a.com
+rateLimit:
+  rps: -1
+`))
+	if len(errs) != 1 || errs[0].Line != 4 {
+		t.Error("errors not equals:", errs)
+	}
+}
@@ -0,0 +1,191 @@
+package gofetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Retry 请求失败时的重试配置
+//
+// 网络错误和 Status 中的状态码会重试，第 n 次重试前等待 Backoff * 2^(n-1)，最多为 MaxBackoff，
+// 另外随机增加 0 到 Jitter 之间的时间；响应包含 Retry-After 时按其等待，超过 MaxBackoff 时不再重试。
+// 默认只重试 GET、HEAD 和 OPTIONS 请求，POST 请求（如登录）需要设置 Post
+type Retry struct {
+	MaxAttempts int           `yaml:"maxAttempts"` // 最多尝试的次数，包含第一次请求，小于 2 时不重试
+	Backoff     time.Duration `yaml:"backoff"`     // 默认为 500ms
+	MaxBackoff  time.Duration `yaml:"maxBackoff"`  // 默认为 30s
+	Jitter      time.Duration `yaml:"jitter"`
+	Status      []int         `yaml:"status"` // 需要重试的状态码，默认为 429、502、503、504
+	Post        bool          `yaml:"post"`
+}
+
+var defaultRetryStatus = []int{
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// WithRetry 设置没有 retry 配置的站点使用的重试配置
+func WithRetry(r Retry) Option {
+	return func(f *Fetch) error {
+		if err := r.validate(); err != nil {
+			return err
+		}
+		f.retry = &r
+		return nil
+	}
+}
+
+// validate 检查重试配置
+func (r *Retry) validate() error {
+	switch {
+	case r.MaxAttempts < 0:
+		return fmt.Errorf("maxAttempts %d is negative", r.MaxAttempts)
+	case r.Backoff < 0:
+		return fmt.Errorf("backoff %v is negative", r.Backoff)
+	case r.MaxBackoff < 0:
+		return fmt.Errorf("maxBackoff %v is negative", r.MaxBackoff)
+	case r.Jitter < 0:
+		return fmt.Errorf("jitter %v is negative", r.Jitter)
+	}
+	for _, code := range r.Status {
+		if code < 100 || code > 599 {
+			return fmt.Errorf("invalid status %d", code)
+		}
+	}
+	return nil
+}
+
+// retryable 判断请求方法是否可以重试
+func (r *Retry) retryable(req *http.Request) bool {
+	switch req.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	case http.MethodPost:
+		return r.Post && (req.Body == nil || req.GetBody != nil)
+	}
+	return false
+}
+
+// retryStatus 判断状态码是否需要重试
+func (r *Retry) retryStatus(code int) bool {
+	status := r.Status
+	if len(status) == 0 {
+		status = defaultRetryStatus
+	}
+	for _, c := range status {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff 返回第 n 次重试前等待的时间
+func (r *Retry) backoff(n int) time.Duration {
+	d, limit := r.Backoff, r.MaxBackoff
+	if d == 0 {
+		d = 500 * time.Millisecond
+	}
+	if limit == 0 {
+		limit = 30 * time.Second
+	}
+	for i := 1; i < n && d < limit; i++ {
+		d *= 2
+	}
+	d = min(d, limit)
+	if r.Jitter > 0 {
+		d += rand.N(r.Jitter)
+	}
+	return d
+}
+
+// maxBackoff 返回等待时间的上限
+func (r *Retry) maxBackoff() time.Duration {
+	if r.MaxBackoff == 0 {
+		return 30 * time.Second
+	}
+	return r.MaxBackoff
+}
+
+// retryAfter 解析 Retry-After，支持秒数和 HTTP 时间
+func retryAfter(resp *http.Response, now time.Time) (time.Duration, bool) {
+	v := resp.Header.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if s, err := strconv.Atoi(v); err == nil && s >= 0 {
+		return time.Duration(s) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return max(t.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+// retryConfig 获取站点的重试配置
+func (f *Fetch) retryConfig(config *Config) *Retry {
+	if config != nil && config.Retry != nil {
+		return config.Retry
+	}
+	return f.retry
+}
+
+// doRetry 按重试配置发送请求，最后一次的响应或错误原样返回
+func (f *Fetch) doRetry(config *Config, req *http.Request) (*http.Response, error) {
+	r := f.retryConfig(config)
+	if r == nil || r.MaxAttempts < 2 || !r.retryable(req) {
+		return f.doOnce(config, req)
+	}
+	ctx := req.Context()
+	for attempt := 1; ; attempt++ {
+		if attempt > 1 {
+			req = req.Clone(ctx)
+			if req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					return nil, err
+				}
+				req.Body = body
+			}
+		}
+		resp, err := f.doOnce(config, req)
+		if attempt >= r.MaxAttempts || ctx.Err() != nil {
+			return resp, err
+		}
+
+		wait := r.backoff(attempt)
+		switch {
+		case err != nil:
+			if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+				return nil, err
+			}
+		case r.retryStatus(resp.StatusCode):
+			if d, ok := retryAfter(resp, time.Now()); ok {
+				if d > r.maxBackoff() {
+					return resp, nil
+				}
+				wait = d
+			}
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+		default:
+			return resp, nil
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		}
+	}
+}
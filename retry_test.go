@@ -0,0 +1,152 @@
+package gofetch
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newRetryFetch(t *testing.T, base, retry string) *Fetch {
+	return newTestFetch(t, `key: retry
+base: `+base+`
+retry:
+`+retry+`
+rules:
+  - type: list
+    match: /.*
+    items: div#Main > div:nth-child(2) > .item
+    itemTitle: .item_title > a
+`)
+}
+
+func TestRetry(t *testing.T) {
+	var count int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch n := atomic.AddInt32(&count, 1); {
+		case n <= 1:
+			w.WriteHeader(http.StatusBadGateway)
+		case n == 2:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			http.ServeFile(w, r, "./testdata/v2ex/tech.html")
+		}
+	}))
+	defer ts.Close()
+
+	f := newRetryFetch(t, ts.URL, `  maxAttempts: 3
+  backoff: 10ms`)
+	res, err := f.Data(ts.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 3 {
+		t.Error("attempts not equals:", count)
+	}
+	if len(res.Items) == 0 {
+		t.Error("items is empty")
+	}
+
+	// 超过 MaxAttempts 后返回最后一次的状态码
+	count = -10
+	if _, err := f.Data(ts.URL + "/"); err == nil || !strings.Contains(err.Error(), "502") {
+		t.Error("error not equals:", err)
+	}
+	if count != -7 {
+		t.Error("attempts not equals:", count)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	var count int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&count, 1)
+		w.Header().Set("Retry-After", "120")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	// Retry-After 超过 maxBackoff 时不重试
+	f := newRetryFetch(t, ts.URL, `  maxAttempts: 3
+  maxBackoff: 1s`)
+	start := time.Now()
+	if _, err := f.Data(ts.URL + "/"); err == nil {
+		t.Error("error is nil")
+	}
+	if count != 1 || time.Since(start) > time.Second {
+		t.Error("attempts not equals:", count, time.Since(start))
+	}
+
+	now := time.Date(2018, 3, 31, 10, 2, 0, 0, time.UTC)
+	resp := &http.Response{Header: http.Header{"Retry-After": {"Sat, 31 Mar 2018 10:02:05 GMT"}}}
+	if d, ok := retryAfter(resp, now); !ok || d != 5*time.Second {
+		t.Error("retryAfter not equals:", d, ok)
+	}
+}
+
+func TestRetryPost(t *testing.T) {
+	var count int32
+	var body string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		body = r.PostForm.Get("username")
+		if atomic.AddInt32(&count, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer ts.Close()
+
+	post := func(f *Fetch) int {
+		req, _ := http.NewRequest(http.MethodPost, ts.URL+"/login", strings.NewReader(url.Values{"username": {"gofetch"}}.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		resp, err := f.do(f.config("retry"), nil, req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	f := newRetryFetch(t, ts.URL, `  maxAttempts: 3
+  backoff: 10ms`)
+	if code := post(f); code != http.StatusServiceUnavailable || count != 1 {
+		t.Error("post should not retry:", code, count)
+	}
+
+	count = 0
+	f = newRetryFetch(t, ts.URL, `  maxAttempts: 3
+  backoff: 10ms
+  post: true`)
+	if code := post(f); code != http.StatusOK || count != 2 || body != "gofetch" {
+		t.Error("post not retried:", code, count, body)
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	r := &Retry{Backoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	want := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second, time.Second}
+	for i, w := range want {
+		if d := r.backoff(i + 1); d != w {
+			t.Error("backoff not equals:", i+1, d)
+		}
+	}
+}
+
+func TestRetryValidate(t *testing.T) {
+	_, errs := parseConfig("retry.yaml", []byte(`key: a
+base: https://a.com
+retry:
+  maxAttempts: 3
+  status: [503, 999]
+`))
+	if len(errs) != 1 || errs[0].Line != 4 {
+		t.Error("errors not equals:", errs)
+	}
+	if _, err := NewWithOptions(nil, WithRetry(Retry{Backoff: -1})); err == nil {
+		t.Error("error is nil")
+	}
+}
@@ -0,0 +1,249 @@
+package gofetch
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// robotsTTL robots.txt 的缓存时间
+const robotsTTL = 24 * time.Hour
+
+// maxRobotsSize 读取 robots.txt 的最大字节数
+const maxRobotsSize = 500 << 10
+
+// WithRobots 请求前检查站点的 robots.txt，userAgent 用于匹配其中的 User-agent 分组，
+// 不允许访问的地址返回 *RobotsDisallowedError，Crawl-delay 作为请求的最小间隔；
+// 站点规则设置 ignoreRobots 时不检查
+func WithRobots(userAgent string) Option {
+	return func(f *Fetch) error {
+		if userAgent == "" {
+			return fmt.Errorf("robots user agent is empty")
+		}
+		f.robotsAgent = userAgent
+		return nil
+	}
+}
+
+// robotsRule robots.txt 中的一条 Allow 或 Disallow
+type robotsRule struct {
+	allow   bool
+	pattern string
+	re      *regexp.Regexp
+}
+
+// robotsGroup 按 User-agent 分组的规则
+type robotsGroup struct {
+	agents     []string
+	rules      []*robotsRule
+	crawlDelay time.Duration
+}
+
+// robots 解析后对某个 User-agent 生效的规则
+type robots struct {
+	rules      []*robotsRule
+	crawlDelay time.Duration
+}
+
+// parseRobots 解析 robots.txt，返回 userAgent 对应分组的规则，没有对应分组时使用 * 分组
+func parseRobots(r io.Reader, userAgent string) *robots {
+	var groups []*robotsGroup
+	var g *robotsGroup
+	inAgents := false
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		k, v, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		k, v = strings.ToLower(strings.TrimSpace(k)), strings.TrimSpace(v)
+		switch k {
+		case "user-agent":
+			if !inAgents {
+				g = &robotsGroup{}
+				groups = append(groups, g)
+				inAgents = true
+			}
+			g.agents = append(g.agents, strings.ToLower(v))
+			continue
+		case "allow", "disallow":
+			if g != nil && v != "" {
+				g.rules = append(g.rules, newRobotsRule(k == "allow", v))
+			}
+		case "crawl-delay":
+			if g != nil {
+				if s, err := strconv.ParseFloat(v, 64); err == nil && s > 0 {
+					g.crawlDelay = time.Duration(s * float64(time.Second))
+				}
+			}
+		}
+		inAgents = false
+	}
+
+	token := strings.ToLower(userAgent)
+	if i := strings.IndexAny(token, "/ "); i >= 0 {
+		token = token[:i]
+	}
+	match := func(agent string) *robots {
+		var rb *robots
+		for _, g := range groups {
+			for _, a := range g.agents {
+				if a == agent {
+					if rb == nil {
+						rb = &robots{}
+					}
+					rb.rules = append(rb.rules, g.rules...)
+					rb.crawlDelay = max(rb.crawlDelay, g.crawlDelay)
+					break
+				}
+			}
+		}
+		return rb
+	}
+	if rb := match(token); rb != nil {
+		return rb
+	}
+	if rb := match("*"); rb != nil {
+		return rb
+	}
+	return &robots{}
+}
+
+// newRobotsRule 将路径规则转换为正则，* 匹配任意字符，结尾的 $ 表示匹配到路径结束
+func newRobotsRule(allow bool, pattern string) *robotsRule {
+	p := strings.TrimSuffix(pattern, "$")
+	expr := "^" + strings.ReplaceAll(regexp.QuoteMeta(p), `\*`, ".*")
+	if p != pattern {
+		expr += "$"
+	}
+	return &robotsRule{allow, pattern, regexp.MustCompile(expr)}
+}
+
+// allowed 判断路径是否允许访问，匹配的规则中最长的生效，长度相同时 Allow 优先
+func (rb *robots) allowed(path string) bool {
+	if path == "/robots.txt" {
+		return true
+	}
+	allow, length := true, -1
+	for _, r := range rb.rules {
+		if !r.re.MatchString(path) {
+			continue
+		}
+		if n := len(r.pattern); n > length || n == length && r.allow {
+			allow, length = r.allow, n
+		}
+	}
+	return allow
+}
+
+// robotsEntry 站点缓存的 robots.txt
+type robotsEntry struct {
+	mu      sync.Mutex
+	robots  *robots
+	expires time.Time
+}
+
+// robotsURL 返回站点 robots.txt 的地址
+func robotsURL(base string) (string, error) {
+	u, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+	return u.Scheme + "://" + u.Host + "/robots.txt", nil
+}
+
+// checkRobots 检查请求的地址是否允许访问
+func (f *Fetch) checkRobots(config *Config, req *http.Request) error {
+	if f.robotsAgent == "" || config == nil || config.IgnoreRobots {
+		return nil
+	}
+	rb, err := f.robots(req.Context(), config)
+	if err != nil {
+		return err
+	}
+	if !rb.allowed(req.URL.RequestURI()) {
+		return &RobotsDisallowedError{req.URL.String(), f.robotsAgent}
+	}
+	return nil
+}
+
+// robots 获取 Config.Base 所在站点的 robots.txt，缓存 robotsTTL，获取失败时不缓存
+//
+// 状态码为 4xx 时允许访问全部地址，其他非 2xx 状态码返回 *HTTPStatusError
+func (f *Fetch) robots(ctx context.Context, config *Config) (*robots, error) {
+	f.robotsMu.Lock()
+	e, ok := f.robotsCache[config.Key]
+	if !ok {
+		e = &robotsEntry{}
+		f.robotsCache[config.Key] = e
+	}
+	f.robotsMu.Unlock()
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.robots != nil && time.Now().Before(e.expires) {
+		return e.robots, nil
+	}
+	ref, err := robotsURL(config.Base)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ref, nil)
+	if err != nil {
+		return nil, err
+	}
+	// robots.txt 使用 WithRobots 的 User-Agent，不参与 userAgents 的轮换
+	req.Header.Set("User-Agent", f.robotsAgent)
+	f.setHeaders(config, nil, req, false)
+	resp, err := f.doRetry(config, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var rb *robots
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode <= 299:
+		rb = parseRobots(io.LimitReader(resp.Body, maxRobotsSize), f.robotsAgent)
+	case resp.StatusCode >= 400 && resp.StatusCode <= 499:
+		rb = &robots{}
+	default:
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody+1))
+		return nil, &HTTPStatusError{ref, resp.StatusCode, truncateBody(body)}
+	}
+	e.robots = rb
+	e.expires = time.Now().Add(robotsTTL)
+	if rb.crawlDelay > 0 {
+		f.setCrawlDelay(config, rb.crawlDelay)
+	}
+	return rb, nil
+}
+
+// setCrawlDelay 将 robots.txt 的 Crawl-delay 设置到站点的频率限制中，站点没有配置 rateLimit 时创建
+func (f *Fetch) setCrawlDelay(config *Config, d time.Duration) {
+	f.mu.Lock()
+	l, ok := f.limiters[config.Key]
+	if !ok {
+		l = newLimiter(RateLimit{})
+		f.limiters[config.Key] = l
+	}
+	f.mu.Unlock()
+	l.mu.Lock()
+	l.crawlDelay = d
+	l.mu.Unlock()
+}
+
+// resetRobots 规则变化后重新获取站点的 robots.txt
+func (f *Fetch) resetRobots(config *Config) {
+	f.robotsMu.Lock()
+	delete(f.robotsCache, config.Key)
+	f.robotsMu.Unlock()
+}
@@ -0,0 +1,146 @@
+package gofetch
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const testRobots = `# robots.txt
+User-agent: *
+Disallow: /private
+Allow: /private/public
+Crawl-delay: 1
+
+User-agent: gofetch
+User-agent: other
+Disallow: /*.php$
+Allow: /index.php
+Disallow: /search # 搜索
+Crawl-delay: 0.5
+`
+
+func TestParseRobots(t *testing.T) {
+	rb := parseRobots(strings.NewReader(testRobots), "GoFetch/1.0 (+https://github.com/ruanjf/gofetch)")
+	if rb.crawlDelay != 500*time.Millisecond {
+		t.Error("crawlDelay not equals:", rb.crawlDelay)
+	}
+	for path, want := range map[string]bool{
+		"/":                     true,
+		"/index.php":            true,
+		"/viewthread.php":       false,
+		"/viewthread.php?tid=1": true,
+		"/search?q=go":          false,
+		"/private":              true,
+		"/robots.txt":           true,
+	} {
+		if got := rb.allowed(path); got != want {
+			t.Error("allowed not equals:", path, got)
+		}
+	}
+
+	rb = parseRobots(strings.NewReader(testRobots), "Mozilla/5.0")
+	if rb.crawlDelay != time.Second {
+		t.Error("crawlDelay not equals:", rb.crawlDelay)
+	}
+	for path, want := range map[string]bool{
+		"/viewthread.php":   true,
+		"/private/a":        false,
+		"/private/public/a": true,
+	} {
+		if got := rb.allowed(path); got != want {
+			t.Error("allowed not equals:", path, got)
+		}
+	}
+
+	rb = parseRobots(strings.NewReader("User-agent: bot\nDisallow: /\n"), "gofetch")
+	if !rb.allowed("/a") {
+		t.Error("empty group should allow all")
+	}
+}
+
+func TestRobots(t *testing.T) {
+	var robotsCount int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			atomic.AddInt32(&robotsCount, 1)
+			w.Write([]byte("User-agent: gofetch\nDisallow: /private\nCrawl-delay: 0.01\n"))
+			return
+		}
+		http.ServeFile(w, r, "./testdata/v2ex/tech.html")
+	}))
+	defer ts.Close()
+
+	rules := `
+rules:
+  - type: list
+    match: /.*
+    items: div#Main > div:nth-child(2) > .item
+    itemTitle: .item_title > a
+`
+	f := newTestFetch(t, "key: robots\nbase: "+ts.URL+rules, WithRobots("gofetch"))
+
+	if _, err := f.Data(ts.URL + "/?tab=tech"); err != nil {
+		t.Error(err)
+	}
+	_, err := f.Data(ts.URL + "/private/1")
+	var re *RobotsDisallowedError
+	if !errors.As(err, &re) || re.UserAgent != "gofetch" {
+		t.Error("error not equals:", err)
+	}
+	if robotsCount != 1 {
+		t.Error("robots.txt count not equals:", robotsCount)
+	}
+	if l := f.limiter(f.config("robots")); l == nil || l.crawlDelay != 10*time.Millisecond {
+		t.Error("crawl-delay not applied")
+	}
+
+	setTestConfig(t, f, "key: robots\nbase: "+ts.URL+"\nignoreRobots: true"+rules)
+	if _, err := f.Data(ts.URL + "/private/1"); err != nil {
+		t.Error(err)
+	}
+	if robotsCount != 1 {
+		t.Error("robots.txt count not equals:", robotsCount)
+	}
+}
+
+func TestRobotsBase(t *testing.T) {
+	var agents []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		agents = append(agents, r.URL.Path+" "+r.Header.Get("User-Agent"))
+		if r.URL.Path == "/robots.txt" {
+			w.Write([]byte("User-agent: *\nDisallow: /forum/private\n"))
+			return
+		}
+		http.ServeFile(w, r, "./testdata/v2ex/tech.html")
+	}))
+	defer ts.Close()
+
+	f := newTestFetch(t, `key: robots
+base: `+ts.URL+`/forum
+userAgents:
+  - agent-a
+  - agent-b
+rules:
+  - type: list
+    match: /.*
+    items: div#Main > div:nth-child(2) > .item
+`, WithRobots("gofetch"))
+
+	if _, err := f.Data(ts.URL + "/forum/list"); err != nil {
+		t.Fatal(err)
+	}
+	var re *RobotsDisallowedError
+	if _, err := f.Data(ts.URL + "/forum/private"); !errors.As(err, &re) {
+		t.Error("error not equals:", err)
+	}
+	// robots.txt 按 Config.Base 获取，使用 WithRobots 的 User-Agent 且不影响轮换
+	want := []string{"/robots.txt gofetch", "/forum/list agent-a"}
+	if len(agents) != len(want) || agents[0] != want[0] || agents[1] != want[1] {
+		t.Error("requests not equals:", agents)
+	}
+}
@@ -0,0 +1,265 @@
+package gofetch
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Session 站点的会话信息
+type Session struct {
+	Cookies  []*Cookie `json:"cookies"`
+	LoggedIn bool      `json:"loggedIn"`
+	Updated  time.Time `json:"updated"`
+}
+
+// SessionStore 会话存储，Load 在会话不存在时返回 nil, nil
+type SessionStore interface {
+	Load(key string) (*Session, error)
+	Save(key string, session *Session) error
+}
+
+// WithSessionStore 设置会话存储，创建时按 Config.Key 加载会话，cookie 更新后保存
+func WithSessionStore(store SessionStore) Option {
+	return func(f *Fetch) error {
+		if store == nil {
+			return errors.New("session store is nil")
+		}
+		f.sessions = store
+		return nil
+	}
+}
+
+// MemorySessionStore 内存会话存储
+type MemorySessionStore struct {
+	mu       sync.Mutex
+	sessions map[string]*Session
+}
+
+// NewMemorySessionStore 创建内存会话存储
+func NewMemorySessionStore() *MemorySessionStore {
+	return &MemorySessionStore{sessions: make(map[string]*Session)}
+}
+
+// Load 读取会话
+func (s *MemorySessionStore) Load(key string) (*Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return copySession(s.sessions[key]), nil
+}
+
+// Save 保存会话
+func (s *MemorySessionStore) Save(key string, session *Session) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions[key] = copySession(session)
+	return nil
+}
+
+func copySession(session *Session) *Session {
+	if session == nil {
+		return nil
+	}
+	c := *session
+	c.Cookies = make([]*Cookie, len(session.Cookies))
+	for i, cookie := range session.Cookies {
+		cc := *cookie
+		c.Cookies[i] = &cc
+	}
+	return &c
+}
+
+// FileSessionStore 文件会话存储，每个站点保存为 Dir 下的一个 JSON 文件，
+// Passphrase 不为空时使用 AES-GCM 加密保存
+type FileSessionStore struct {
+	Dir        string
+	Passphrase string
+
+	mu   sync.Mutex
+	salt []byte
+	aead cipher.AEAD
+}
+
+// NewFileSessionStore 创建文件会话存储
+func NewFileSessionStore(dir, passphrase string) *FileSessionStore {
+	return &FileSessionStore{Dir: dir, Passphrase: passphrase}
+}
+
+type encryptedSession struct {
+	Salt  []byte `json:"salt"`
+	Nonce []byte `json:"nonce"`
+	Data  []byte `json:"data"`
+}
+
+func (s *FileSessionStore) path(key string) string {
+	return filepath.Join(s.Dir, url.PathEscape(key)+".json")
+}
+
+// Load 读取会话
+func (s *FileSessionStore) Load(key string) (*Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	content, err := os.ReadFile(s.path(key))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if s.Passphrase != "" {
+		es := &encryptedSession{}
+		err = json.Unmarshal(content, es)
+		if err != nil {
+			return nil, err
+		}
+		gcm, err := s.cipher(es.Salt)
+		if err != nil {
+			return nil, err
+		}
+		content, err = gcm.Open(nil, es.Nonce, es.Data, []byte(key))
+		if err != nil {
+			return nil, errors.New("session decrypt failed: " + err.Error())
+		}
+	}
+	session := &Session{}
+	err = json.Unmarshal(content, session)
+	if err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// Save 保存会话
+func (s *FileSessionStore) Save(key string, session *Session) error {
+	content, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Passphrase != "" {
+		salt := s.salt
+		if salt == nil {
+			salt = make([]byte, 16)
+			rand.Read(salt)
+		}
+		gcm, err := s.cipher(salt)
+		if err != nil {
+			return err
+		}
+		es := &encryptedSession{Salt: salt, Nonce: make([]byte, gcm.NonceSize())}
+		rand.Read(es.Nonce)
+		es.Data = gcm.Seal(nil, es.Nonce, content, []byte(key))
+		content, err = json.Marshal(es)
+		if err != nil {
+			return err
+		}
+	}
+	err = os.MkdirAll(s.Dir, 0700)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(s.Dir, ".session-*")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(content)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path(key))
+}
+
+// cipher 根据 Passphrase 和 salt 生成密钥，最近一次使用的密钥会被缓存，调用时需要持有锁
+func (s *FileSessionStore) cipher(salt []byte) (cipher.AEAD, error) {
+	if s.aead != nil && bytes.Equal(s.salt, salt) {
+		return s.aead, nil
+	}
+	key, err := pbkdf2.Key(sha256.New, s.Passphrase, salt, 100000, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	s.salt, s.aead = salt, gcm
+	return gcm, nil
+}
+
+// loadSessions 从会话存储中加载所有站点的会话
+func (f *Fetch) loadSessions() {
+	if f.sessions == nil {
+		return
+	}
+	for key, jar := range f.Cookie {
+		session, err := f.sessions.Load(key)
+		if err != nil {
+			log.Println(key, err)
+			continue
+		}
+		if session == nil {
+			continue
+		}
+		jar.Add(session.Cookies...)
+		f.loggedIn[key] = session.LoggedIn
+		f.saved[key] = jar.changes()
+	}
+}
+
+// saveSession 在站点的 cookie 或登录状态变化后保存会话
+func (f *Fetch) saveSession(key string, force bool) {
+	if f.sessions == nil {
+		return
+	}
+	jar := f.CookieJar(key)
+	f.sessionMu.Lock()
+	defer f.sessionMu.Unlock()
+	changes := jar.changes()
+	if !force && f.saved[key] == changes {
+		return
+	}
+	f.saved[key] = changes
+	err := f.sessions.Save(key, &Session{
+		Cookies:  jar.All(),
+		LoggedIn: f.IsLoggedIn(key),
+		Updated:  time.Now(),
+	})
+	if err != nil {
+		log.Println(key, err)
+	}
+}
+
+// IsLoggedIn 站点是否已经登录
+func (f *Fetch) IsLoggedIn(key string) bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.loggedIn[key]
+}
+
+func (f *Fetch) setLoggedIn(key string, loggedIn bool) {
+	f.mu.Lock()
+	f.loggedIn[key] = loggedIn
+	f.mu.Unlock()
+	f.saveSession(key, true)
+}
@@ -0,0 +1,86 @@
+package gofetch
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileSessionStore(t *testing.T) {
+	for _, passphrase := range []string{"", "secret"} {
+		dir := t.TempDir()
+		store := NewFileSessionStore(dir, passphrase)
+		session := &Session{
+			Cookies:  []*Cookie{{Name: "cdb_auth", Value: "abc", Domain: "www.hi-pda.com", Path: "/", HostOnly: true}},
+			LoggedIn: true,
+		}
+		err := store.Save("hipda", session)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		content, _ := os.ReadFile(filepath.Join(dir, "hipda.json"))
+		if (passphrase != "") == strings.Contains(string(content), "cdb_auth") {
+			t.Error("encrypted not equals:", passphrase, string(content))
+		}
+
+		s, err := NewFileSessionStore(dir, passphrase).Load("hipda")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if s == nil || !s.LoggedIn || len(s.Cookies) != 1 || s.Cookies[0].Value != "abc" {
+			t.Error("session not equals:", s)
+		}
+
+		s, err = store.Load("v2ex")
+		if s != nil || err != nil {
+			t.Error("session found:", s, err)
+		}
+	}
+
+	store := NewFileSessionStore(t.TempDir(), "secret")
+	store.Save("hipda", &Session{})
+	_, err := NewFileSessionStore(store.Dir, "wrong").Load("hipda")
+	if err == nil {
+		t.Error("wrong passphrase must fail")
+	}
+}
+
+func TestFetchSession(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := r.Cookie("cdb_auth"); err != nil {
+			http.SetCookie(w, &http.Cookie{Name: "cdb_auth", Value: "abc", Path: "/", MaxAge: 300})
+		}
+		http.ServeFile(w, r, "./testdata/hipda/index.html")
+	}))
+	defer ts.Close()
+
+	store := NewMemorySessionStore()
+	f, err := NewWithOptions([]string{"./rule/hipda.yaml"}, WithSessionStore(store))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	f.Config["hipda"].Base = ts.URL
+	_, err = f.Index("hipda")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	s, _ := store.Load("hipda")
+	if s == nil || len(s.Cookies) != 1 || s.Cookies[0].Name != "cdb_auth" {
+		t.Error("session not saved:", s)
+		return
+	}
+
+	f, _ = NewWithOptions([]string{"./rule/hipda.yaml"}, WithSessionStore(store))
+	u, _ := http.NewRequest("GET", ts.URL+"/index.php", nil)
+	cs := f.CookieJar("hipda").Cookies(u.URL)
+	if len(cs) != 1 || cs[0].Value != "abc" {
+		t.Error("session not loaded:", cs)
+	}
+}
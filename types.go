@@ -0,0 +1,243 @@
+package gofetch
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// FieldType 字段的值类型
+//
+// Type 支持 int、float、bool、time、date、duration、url，为空时为字符串，
+// time 类型按 Layout 解析，Layout 为空时使用 RFC 3339；
+// date 类型在解析页面时通过 ParseDate 转换为 RFC 3339 格式，Layout 不为空时优先使用
+type FieldType struct {
+	Type   string
+	Layout string
+}
+
+// UnmarshalYAML 支持 "int" 这样只写类型名的简写
+func (ft *FieldType) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		ft.Type = value.Value
+		return nil
+	}
+	type plain FieldType
+	return value.Decode((*plain)(ft))
+}
+
+// validate 检查类型是否支持
+func (ft *FieldType) validate() error {
+	switch ft.Type {
+	case "", "int", "float", "bool", "duration", "url":
+		if ft.Layout != "" {
+			return fmt.Errorf("layout is only for time and date, got type %q", ft.Type)
+		}
+	case "time", "date":
+	default:
+		return fmt.Errorf("unknown type %q", ft.Type)
+	}
+	return nil
+}
+
+// convert 将字符串转换为对应类型的值，空字符串返回 nil
+func (ft *FieldType) convert(s string) (interface{}, error) {
+	if ft == nil || ft.Type == "" {
+		return s, nil
+	}
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+	switch ft.Type {
+	case "int":
+		return strconv.ParseInt(strings.ReplaceAll(s, ",", ""), 10, 64)
+	case "float":
+		return strconv.ParseFloat(strings.ReplaceAll(s, ",", ""), 64)
+	case "bool":
+		switch strings.ToLower(s) {
+		case "yes", "on", "y":
+			return true, nil
+		case "no", "off", "n":
+			return false, nil
+		}
+		return strconv.ParseBool(s)
+	case "time":
+		layout := ft.Layout
+		if layout == "" {
+			layout = time.RFC3339
+		}
+		return time.Parse(layout, s)
+	case "date":
+		return time.Parse(time.RFC3339, s)
+	case "duration":
+		return time.ParseDuration(s)
+	case "url":
+		return url.Parse(s)
+	}
+	return nil, fmt.Errorf("unknown type %q", ft.Type)
+}
+
+// FieldError 字段的值转换为声明的类型时失败
+type FieldError struct {
+	Item  int // 为 -1 时表示 Content 中的字段
+	Field string
+	Value string
+	Type  string
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	where := "content"
+	if e.Item >= 0 {
+		where = "item " + strconv.Itoa(e.Item)
+	}
+	return fmt.Sprintf("%s field %q: cannot convert %q to %s: %v", where, e.Field, e.Value, e.Type, e.Err)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// FieldErrors 多个字段的类型转换错误
+type FieldErrors []*FieldError
+
+func (e FieldErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Unwrap 支持 errors.As 获取其中的 *FieldError
+func (e FieldErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+	return errs
+}
+
+// TypedRes 字段值转换为声明类型后的数据
+//
+// int 为 int64，float 为 float64，bool 为 bool，time 和 date 为 time.Time，
+// duration 为 time.Duration，url 为 *url.URL，未声明类型的字段为 string，空值为 nil
+type TypedRes struct {
+	Content map[string]interface{}
+	Categories,
+	Items []map[string]interface{}
+}
+
+// Typed 按 Types 转换全部字段，转换失败的字段值为 nil，错误为 FieldErrors
+func (r *Res) Typed() (*TypedRes, error) {
+	var errs FieldErrors
+	convertMap := func(i int, m map[string]string) map[string]interface{} {
+		if m == nil {
+			return nil
+		}
+		tm := make(map[string]interface{}, len(m))
+		for k, v := range m {
+			tv, err := r.convert(i, k, v)
+			if err != nil {
+				errs = append(errs, err.(*FieldError))
+			}
+			tm[k] = tv
+		}
+		return tm
+	}
+	tr := &TypedRes{Content: convertMap(-1, r.Content)}
+	for _, c := range r.Categories {
+		tc := make(map[string]interface{}, len(c))
+		for k, v := range c {
+			tc[k] = v
+		}
+		tr.Categories = append(tr.Categories, tc)
+	}
+	for i, item := range r.Items {
+		tr.Items = append(tr.Items, convertMap(i, item))
+	}
+	if len(errs) > 0 {
+		return tr, errs
+	}
+	return tr, nil
+}
+
+// ContentValue 获取 Content 中 name 字段转换为声明类型后的值
+func (r *Res) ContentValue(name string) (interface{}, error) {
+	return r.convert(-1, name, r.Content[name])
+}
+
+// ItemValue 获取第 i 个 item 中 name 字段转换为声明类型后的值
+func (r *Res) ItemValue(i int, name string) (interface{}, error) {
+	if i < 0 || i >= len(r.Items) {
+		return nil, fmt.Errorf("item index %d out of range", i)
+	}
+	return r.convert(i, name, r.Items[i][name])
+}
+
+// ItemInt 获取第 i 个 item 中 int 类型字段的值，空值返回 0，字段没有声明为 int 类型时返回错误
+func (r *Res) ItemInt(i int, name string) (int64, error) {
+	if err := r.checkType(name, "int"); err != nil {
+		return 0, err
+	}
+	v, err := r.ItemValue(i, name)
+	n, _ := v.(int64)
+	return n, err
+}
+
+// ItemTime 获取第 i 个 item 中 time 或 date 类型字段的值，空值返回零值，字段没有声明为这两种类型时返回错误
+func (r *Res) ItemTime(i int, name string) (time.Time, error) {
+	if err := r.checkType(name, "time", "date"); err != nil {
+		return time.Time{}, err
+	}
+	v, err := r.ItemValue(i, name)
+	t, _ := v.(time.Time)
+	return t, err
+}
+
+// checkType 检查字段声明的类型是否为 types 之一
+func (r *Res) checkType(name string, types ...string) error {
+	declared := ""
+	if ft := r.Types[name]; ft != nil {
+		declared = ft.Type
+	}
+	for _, t := range types {
+		if declared == t {
+			return nil
+		}
+	}
+	if declared == "" {
+		declared = "string"
+	}
+	return fmt.Errorf("field %q is declared as %s, not %s", name, declared, strings.Join(types, " or "))
+}
+
+func (r *Res) convert(i int, name, value string) (interface{}, error) {
+	ft := r.Types[name]
+	v, err := ft.convert(value)
+	if err != nil {
+		return nil, &FieldError{i, name, value, ft.Type, err}
+	}
+	return v, nil
+}
+
+// types 合并规则中 Types 和字段上声明的类型
+func (r *Rule) types() map[string]*FieldType {
+	types := make(map[string]*FieldType, len(r.Types))
+	for k, ft := range r.Types {
+		types[k] = ft
+	}
+	for _, fields := range [][]*Field{r.Content, r.Fields} {
+		for _, field := range fields {
+			if field != nil && field.Type != "" {
+				types[field.Name] = &FieldType{field.Type, field.Layout}
+			}
+		}
+	}
+	return types
+}
@@ -0,0 +1,101 @@
+package gofetch
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestResTyped(t *testing.T) {
+	res := &Res{
+		Content: map[string]string{"title": "t", "sticky": "yes"},
+		Items: []map[string]string{
+			{"no": "1", "time": "2018-3-31 10:02", "score": "1.5", "link": "https://www.hi-pda.com/forum/viewthread.php?tid=1", "ttl": "5m"},
+			{"no": "abc", "time": "", "score": "1,001.5"},
+		},
+		Types: map[string]*FieldType{
+			"sticky": {Type: "bool"},
+			"no":     {Type: "int"},
+			"time":   {Type: "time", Layout: "2006-1-2 15:04"},
+			"score":  {Type: "float"},
+			"link":   {Type: "url"},
+			"ttl":    {Type: "duration"},
+		},
+	}
+
+	tr, err := res.Typed()
+	var errs FieldErrors
+	if !errors.As(err, &errs) || len(errs) != 1 {
+		t.Error("errors not equals:", err)
+		return
+	}
+	fe := errs[0]
+	if fe.Item != 1 || fe.Field != "no" || fe.Value != "abc" || fe.Type != "int" || !errors.Is(err, strconv.ErrSyntax) {
+		t.Error("error not equals:", fe)
+	}
+
+	item := tr.Items[0]
+	if item["no"] != int64(1) ||
+		!item["time"].(time.Time).Equal(time.Date(2018, 3, 31, 10, 2, 0, 0, time.UTC)) ||
+		item["score"] != 1.5 ||
+		item["ttl"] != 5*time.Minute ||
+		item["link"] == nil {
+		t.Error("item not equals:", item)
+	}
+	if tr.Items[1]["no"] != nil || tr.Items[1]["time"] != nil || tr.Items[1]["score"] != 1001.5 {
+		t.Error("item not equals:", tr.Items[1])
+	}
+	if tr.Content["sticky"] != true || tr.Content["title"] != "t" {
+		t.Error("content not equals:", tr.Content)
+	}
+
+	v, err := res.ContentValue("sticky")
+	if v != true || err != nil {
+		t.Error("content value not equals:", v, err)
+	}
+	_, err = res.ItemValue(2, "no")
+	if err == nil {
+		t.Error("out of range must fail")
+	}
+}
+
+func TestDataTypes(t *testing.T) {
+	_, res, err := makeData(&testConfig{
+		"./testdata/fields/v2ex.yaml",
+		"./testdata/v2ex/tech.html",
+		"https://www.v2ex.com/?tab=tech",
+	}, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	n, err := res.ItemInt(0, "replies")
+	if n != 214 || err != nil {
+		t.Error("replies not equals:", n, err)
+	}
+	// 字段没有声明为对应类型时返回错误，而不是零值
+	if _, err := res.ItemInt(0, "title"); err == nil {
+		t.Error("undeclared int field must return error")
+	}
+	if _, err := res.ItemTime(0, "replies"); err == nil {
+		t.Error("int field must not convert to time")
+	}
+	tr, err := res.Typed()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if tr.Items[1]["replies"] != int64(1) || tr.Items[1]["views"] != int64(0) || tr.Items[1]["replyCount"] != "回复 1" {
+		t.Error("item not equals:", tr.Items[1])
+	}
+
+	ft := &FieldType{Type: "int", Layout: "2006"}
+	if ft.validate() == nil {
+		t.Error("layout must be invalid")
+	}
+	ft = &FieldType{Type: "timestamp"}
+	if ft.validate() == nil {
+		t.Error("type must be invalid")
+	}
+}
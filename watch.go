@@ -0,0 +1,98 @@
+package gofetch
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"time"
+)
+
+// watchedFile 已加载的本地规则文件
+type watchedFile struct {
+	modTime time.Time
+	size    int64
+	key     string
+}
+
+// watchFile 记录本地规则文件的状态，用于检查文件是否变化
+func (f *Fetch) watchFile(file string, info os.FileInfo, key string) {
+	if info == nil {
+		return
+	}
+	f.files[file] = &watchedFile{info.ModTime(), info.Size(), key}
+}
+
+// Watch 每隔 interval 调用一次 Reload，直到 ctx 被取消
+func (f *Fetch) Watch(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if err := f.Reload(); err != nil {
+				log.Println(err)
+			}
+		}
+	}
+}
+
+// Reload 检查已加载的本地规则文件，重新解析变化的文件，校验通过后替换对应的规则；
+// 校验失败的文件会被拒绝并继续使用之前的规则，返回的 ConfigErrors 包含被拒绝的原因
+func (f *Fetch) Reload() error {
+	f.reloadMu.Lock()
+	defer f.reloadMu.Unlock()
+
+	var errs ConfigErrors
+	for file, wf := range f.files {
+		info, err := os.Stat(file)
+		if err != nil {
+			continue
+		}
+		if info.ModTime().Equal(wf.modTime) && info.Size() == wf.size {
+			continue
+		}
+		wf.modTime, wf.size = info.ModTime(), info.Size()
+
+		content, err := os.ReadFile(file)
+		if err != nil {
+			errs = append(errs, &ConfigError{file, 0, err.Error()})
+			continue
+		}
+		config, ces := parseConfig(file, content)
+		if len(ces) > 0 {
+			errs = append(errs, ces...)
+			continue
+		}
+		if config.Key != wf.key {
+			if ce := f.checkReloadKey(file, config); ce != nil {
+				errs = append(errs, ce)
+				continue
+			}
+		}
+
+		f.mu.Lock()
+		if config.Key != wf.key && wf.key != "" {
+			delete(f.Config, wf.key)
+		}
+		f.setConfig(config)
+		f.mu.Unlock()
+		wf.key = config.Key
+		log.Println("reload", file)
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// checkReloadKey 检查修改后的 key 是否与其他文件的规则重复
+func (f *Fetch) checkReloadKey(file string, config *Config) *ConfigError {
+	old := f.config(config.Key)
+	if old == nil || old.bundled {
+		return nil
+	}
+	return &ConfigError{file, config.keyLine, fmt.Sprintf("duplicate key %q, first defined in %s:%d", config.Key, old.source, old.keyLine)}
+}
@@ -0,0 +1,90 @@
+package gofetch
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeRule(t *testing.T, path, old, new string, mod time.Time) {
+	content, err := os.ReadFile("./rule/v2ex.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(path, []byte(strings.Replace(string(content), old, new, 1)), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Chtimes(path, mod, mod)
+}
+
+func TestReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "v2ex.yaml")
+	now := time.Now()
+	writeRule(t, path, "", "", now.Add(-time.Hour))
+
+	f, err := New(path)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	old := f.Config["v2ex"]
+	jar := f.CookieJar("v2ex")
+
+	err = f.Reload()
+	if err != nil || f.Config["v2ex"] != old {
+		t.Error("unchanged config reloaded:", err)
+	}
+
+	writeRule(t, path, "itemTitle: .item_title > a", "itemTitle: .item_title a", now.Add(-time.Minute))
+	err = f.Reload()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	config := f.Config["v2ex"]
+	if config == old || config.Rules[1].Selectors["itemTitle"] != ".item_title a" {
+		t.Error("config not reloaded")
+		return
+	}
+	if f.CookieJar("v2ex") != jar || f.httpClient(config).Jar != jar {
+		t.Error("cookie jar not kept")
+	}
+
+	writeRule(t, path, "itemTitle: .item_title > a", "itemTitle: .item_title >", now)
+	err = f.Reload()
+	var errs ConfigErrors
+	if !errors.As(err, &errs) || len(errs) != 1 || !strings.Contains(errs[0].Error(), "itemTitle") {
+		t.Error("broken config not rejected:", err)
+	}
+	if f.Config["v2ex"] != config {
+		t.Error("broken config used")
+	}
+}
+
+func TestWatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "v2ex.yaml")
+	writeRule(t, path, "", "", time.Now().Add(-time.Hour))
+	f, _ := New(path)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- f.Watch(ctx, 10*time.Millisecond)
+	}()
+	writeRule(t, path, "url: /?tab=tech", "url: /?tab=hot", time.Now())
+	for i := 0; i < 100 && f.config("v2ex").Index.URL != "/?tab=hot"; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Error("watch not stopped:", err)
+	}
+	if f.config("v2ex").Index.URL != "/?tab=hot" {
+		t.Error("config not reloaded")
+	}
+}
@@ -0,0 +1,52 @@
+package gofetch
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/antchfx/htmlquery"
+	"github.com/antchfx/xpath"
+	"golang.org/x/net/html"
+)
+
+// xpathPrefix 选择器以此开头时按 XPath 表达式处理
+const xpathPrefix = "xpath:"
+
+var xpathCache sync.Map
+
+// find 在 s 中查找选择器匹配的元素
+//
+// 以 xpath: 开头的选择器按 XPath 表达式对 s 中的每个节点求值，相对于当前节点时使用 ./ 或 .// 开头，
+// 返回的节点与 CSS 选择器的结果一样用于取文本、HTML 和链接
+func find(s *goquery.Selection, sel string) *goquery.Selection {
+	expr, ok := strings.CutPrefix(sel, xpathPrefix)
+	if !ok {
+		return s.Find(sel)
+	}
+	// FilterNodes 返回使用新切片的空结果，Slice(0, 0) 与 s 共用底层数组，AddNodes 会修改 s
+	empty := s.FilterNodes()
+	exp, err := compileXPath(expr)
+	if err != nil {
+		return empty
+	}
+	var nodes []*html.Node
+	for _, n := range s.Nodes {
+		nodes = append(nodes, htmlquery.QuerySelectorAll(n, exp)...)
+	}
+	return empty.AddNodes(nodes...)
+}
+
+// compileXPath 编译并缓存 XPath 表达式
+func compileXPath(expr string) (*xpath.Expr, error) {
+	expr = strings.TrimSpace(expr)
+	if exp, ok := xpathCache.Load(expr); ok {
+		return exp.(*xpath.Expr), nil
+	}
+	exp, err := xpath.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	xpathCache.Store(expr, exp)
+	return exp, nil
+}
@@ -0,0 +1,64 @@
+package gofetch
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestDataXPath(t *testing.T) {
+	config, res, err := makeData(&testConfig{
+		"./testdata/xpath/v2ex.yaml",
+		"./testdata/v2ex/tech.html",
+		"https://www.v2ex.com/?tab=tech",
+	}, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if res == nil || len(res.Items) != 50 {
+		t.Error("items not equals:", res)
+		return
+	}
+	item := res.Items[0]
+	if item["title"] != "年会被耍了 感觉很没意思 所以接下来该干啥呢" ||
+		item["link"] != config.Base+"/t/416297#reply214" ||
+		item["author"] != "MrFireAwayH" ||
+		item["authorLink"] != config.Base+"/member/MrFireAwayH" ||
+		item["replyCount"] != "214" ||
+		item["node"] != "程序员" ||
+		item["nodeLink"] != config.Base+"/go/programmer" ||
+		item["nodeHref"] != "/go/programmer" {
+		t.Error("item not equals:", item)
+	}
+}
+
+func TestFindXPath(t *testing.T) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(
+		`<dl><dt>作者</dt><dd><a href="/u/1">alex</a></dd><dt>时间</dt><dd>2018-3-31 10:02</dd></dl>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dd := find(doc.Selection, `xpath://dt[text()="时间"]/following-sibling::dd[1]`)
+	if dd.Text() != "2018-3-31 10:02" {
+		t.Error("text not equals:", dd.Text())
+	}
+	a := find(doc.Selection, `xpath://dt[text()="作者"]/following-sibling::dd[1]`)
+	if find(a, "a").AttrOr("href", "") != "/u/1" || find(a, "xpath:./a").Text() != "alex" {
+		t.Error("link not equals:", a.Text())
+	}
+	if find(doc.Selection, "xpath://dt[").Length() != 0 {
+		t.Error("invalid xpath must be empty")
+	}
+	if find(doc.Selection, "dd").Length() != 2 {
+		t.Error("document must not be changed by xpath")
+	}
+
+	var errs []error
+	validateSelector("xpath://dt[", func(err error) { errs = append(errs, err) })
+	validateSelector(`xpath://dt[text()="作者"]`, func(err error) { errs = append(errs, err) })
+	if len(errs) != 1 {
+		t.Error("errors not equals:", errs)
+	}
+}